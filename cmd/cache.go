@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Method-Security/webassess/internal/cache"
+	"github.com/spf13/cobra"
+)
+
+// InitCacheCommand initializes the cache command for the webassess CLI. Its subcommands manage the on-disk cache of
+// model responses. They do not perform any inference, so they skip the Ollama checks run by the root command.
+func (a *WebAssess) InitCacheCommand() {
+	cacheCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the cache of model responses",
+		Long:  `Manage the on-disk cache of model responses`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return a.loadConfig(cmd)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+
+	pruneCmd := &cobra.Command{
+		Use:   "prune",
+		Short: "Remove expired cache entries and enforce the size limit",
+		Long: `Remove cache entries older than the configured TTL, then remove the least recently used entries until the
+cache fits within the configured size limit`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			all, err := cmd.Flags().GetBool("all")
+			if err != nil {
+				return err
+			}
+
+			responseCache, err := cache.Open(a.Config.Cache.Dir, time.Duration(a.Config.Cache.TTL), a.Config.Cache.MaxBytes)
+			if err != nil {
+				return err
+			}
+			result, err := responseCache.Prune(all)
+			if err != nil {
+				return err
+			}
+
+			_, err = fmt.Fprintf(cmd.OutOrStdout(), "Removed %d entries (%s), %d entries (%s) remain in %s\n",
+				result.Removed, formatSize(result.RemovedBytes), result.Entries, formatSize(result.Bytes), responseCache.Dir)
+			return err
+		},
+	}
+	pruneCmd.Flags().Bool("all", false, "Remove every cache entry")
+
+	cacheCmd.AddCommand(pruneCmd)
+	a.RootCmd.AddCommand(cacheCmd)
+}
+
+func formatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,107 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Method-Security/webassess/internal/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// InitConfigCommand initializes the config command for the webassess CLI. Its subcommands inspect the configuration
+// resolved from the configuration file, WEBASSESS_* environment variables and command line flags. They do not
+// perform any inference, so they skip the Ollama checks run by the root command.
+func (a *WebAssess) InitConfigCommand() {
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the webassess configuration",
+		Long:  `Inspect the webassess configuration`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			return a.loadConfig(cmd)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration",
+		Long: `Print the effective configuration. Settings are resolved in the following order, with later sources taking
+precedence: built-in defaults, the configuration file, the selected profile, WEBASSESS_* environment variables and
+command line flags. The values of fetch headers, which commonly carry credentials, are redacted unless
+--show-secrets is set.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := cmd.Flags().GetString("format")
+			if err != nil {
+				return err
+			}
+			showSecrets, err := cmd.Flags().GetBool("show-secrets")
+			if err != nil {
+				return err
+			}
+			cfg := a.Config
+			if !showSecrets {
+				cfg = cfg.Redacted()
+			}
+
+			var data []byte
+			switch strings.ToLower(format) {
+			case "yaml":
+				data, err = yaml.Marshal(cfg)
+			case "json":
+				data, err = json.MarshalIndent(cfg, "", "  ")
+			case "toml":
+				var buf bytes.Buffer
+				err = toml.NewEncoder(&buf).Encode(cfg)
+				data = buf.Bytes()
+			default:
+				return fmt.Errorf("invalid format %q. Valid formats are: yaml, json, toml", format)
+			}
+			if err != nil {
+				return err
+			}
+
+			if a.ConfigPath != "" {
+				cmd.PrintErrf("Loaded configuration file: %s\n", a.ConfigPath)
+			} else {
+				cmd.PrintErrf("No configuration file found. Searched: %s\n", strings.Join(config.ConfigSearchPaths(), ", "))
+			}
+			_, err = fmt.Fprint(cmd.OutOrStdout(), strings.TrimRight(string(data), "\n")+"\n")
+			return err
+		},
+	}
+	showCmd.Flags().String("format", "yaml", "Format to print the configuration in (yaml, json, toml)")
+	showCmd.Flags().Bool("show-secrets", false, "Print the values of fetch headers instead of redacting them")
+
+	profilesCmd := &cobra.Command{
+		Use:   "profiles",
+		Short: "List the available assessment profiles",
+		Long:  `List the built-in assessment profiles along with those defined in the configuration file`,
+		Run: func(cmd *cobra.Command, args []string) {
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(writer, "NAME\tSOURCE\tDESCRIPTION")
+			for _, name := range a.Config.ProfileNames() {
+				profile := a.Config.Profiles[name]
+				source := "config"
+				if profile.BuiltIn {
+					source = "built-in"
+				}
+				if name == a.Config.Profile {
+					name += " (selected)"
+				}
+				fmt.Fprintf(writer, "%s\t%s\t%s\n", name, source, profile.Description)
+			}
+			_ = writer.Flush()
+		},
+	}
+
+	configCmd.AddCommand(showCmd)
+	configCmd.AddCommand(profilesCmd)
+	a.RootCmd.AddCommand(configCmd)
+}
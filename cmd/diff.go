@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/Method-Security/webassess/internal/diff"
+	"github.com/Method-Security/webassess/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// InitDiffCommand initializes the diff command for the webassess CLI. This command compares two previously written
+// reports and outputs the new, resolved and unchanged findings for every target, along with content hash changes.
+// It does not perform any inference, so it skips the Ollama checks run by the root command.
+func (a *WebAssess) InitDiffCommand() {
+	diffCmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compare a webassess report against a baseline report",
+		Long:  `Compare a webassess report against a baseline report, matching findings by their stable fingerprint`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := a.loadConfig(cmd); err != nil {
+				return err
+			}
+			if err := a.validateExitPolicy(); err != nil {
+				return err
+			}
+			if err := a.configureOutput(cmd); err != nil {
+				return err
+			}
+			if report.IsFormat(a.OutputConfig.Output.String()) {
+				return errors.New("the diff command only supports the signal, json and yaml output formats")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			baselinePath, err := cmd.Flags().GetString("baseline")
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+			currentPath, err := cmd.Flags().GetString("current")
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+
+			baseline, err := diff.LoadReports(baselinePath)
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+			current, err := diff.LoadReports(currentPath)
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+
+			a.OutputSignal.Content = diff.Compare(baselinePath, baseline, current)
+		},
+	}
+
+	diffCmd.Flags().String("baseline", "", "Path to the previous JSON report to compare against")
+	diffCmd.Flags().String("current", "", "Path to the current JSON report")
+	_ = diffCmd.MarkFlagRequired("baseline")
+	_ = diffCmd.MarkFlagRequired("current")
+
+	a.RootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,133 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/eval"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/report"
+	"github.com/spf13/cobra"
+)
+
+// InitEvalCommand initializes the eval command for the webassess CLI. This command runs the assessment pipeline over a
+// labeled corpus of local fixtures and reports precision, recall and F1 per finding category, along with latency and
+// token usage, for one or more model and prompt variants side by side.
+func (a *WebAssess) InitEvalCommand() {
+	evalCmd := &cobra.Command{
+		Use:   "eval",
+		Short: "Evaluate models and prompts against a labeled corpus",
+		Long:  `Evaluate models and prompts against a labeled corpus of HTML and JavaScript fixtures with expected findings`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			if report.IsFormat(a.OutputConfig.Output.String()) {
+				return errors.New("the eval command only supports the signal, json and yaml output formats")
+			}
+			return nil
+		},
+		Run: func(cmd *cobra.Command, args []string) {
+			corpusPath, err := cmd.Flags().GetString("corpus")
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+			models, err := cmd.Flags().GetStringSlice("models")
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+			allowDownload, err := cmd.Flags().GetBool("allow-download")
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+
+			corpus, err := eval.LoadCorpus(corpusPath)
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+
+			options, err := a.assessmentOptions(cmd.Context())
+			if err != nil {
+				a.OutputSignal.AddError(err)
+				return
+			}
+			// Cached responses would make the latency and token usage meaningless
+			options.Cache = nil
+
+			variants := corpus.Variants
+			if len(models) > 0 {
+				variants = []eval.Variant{}
+				for _, model := range models {
+					variants = append(variants, eval.Variant{Name: model, Model: model})
+				}
+			}
+			if len(variants) == 0 {
+				variants = []eval.Variant{{Name: a.RootFlags.OllamaModel.Name, Model: a.RootFlags.OllamaModel.Name}}
+			}
+
+			for _, variant := range variants {
+				if !ollama.ModelReady(cmd.Context(), a.RootFlags.OllamaURL, variant.Model) && allowDownload {
+					if !a.Config.Ollama.AllowedModels.IsAllowed(variant.Model) {
+						a.OutputSignal.AddError(fmt.Errorf("ollama model '%s' is not in the allowed models list", variant.Model))
+						return
+					}
+					if err := ollama.DownloadOllamaModel(cmd.Context(), variant.Model, a.RootFlags.OllamaURL, a.progressReporter(cmd.Context())); err != nil {
+						a.OutputSignal.AddError(errors.New("failed to download ollama model: " + err.Error()))
+						return
+					}
+				}
+				// Pinned models are verified before any assessment runs. Models that are not installed are reported
+				// by the variant itself.
+				if model, err := ollama.GetModel(cmd.Context(), a.RootFlags.OllamaURL, variant.Model); err == nil {
+					if err := a.Config.Ollama.AllowedModels.VerifyDigest(model); err != nil {
+						a.OutputSignal.AddError(err)
+						return
+					}
+				}
+			}
+
+			result := eval.Run(cmd.Context(), corpus, variants, a.RootFlags.OllamaURL, options)
+			if !a.RootFlags.Quiet {
+				writeEvalSummary(cmd, result)
+			}
+			a.OutputSignal.Content = result
+		},
+	}
+
+	evalCmd.Flags().String("corpus", "", "Path to the corpus manifest listing the fixtures and their expected findings")
+	evalCmd.Flags().StringSlice("models", []string{}, "Models to compare, overriding the variants of the corpus manifest")
+	_ = evalCmd.MarkFlagRequired("corpus")
+
+	a.RootCmd.AddCommand(evalCmd)
+}
+
+// writeEvalSummary prints a side by side comparison of the variants to stderr, so that it does not interfere with the
+// report written to stdout.
+func writeEvalSummary(cmd *cobra.Command, result webassess.EvalReport) {
+	w := tabwriter.NewWriter(cmd.ErrOrStderr(), 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(w, "VARIANT\tMODEL\tPRECISION\tRECALL\tF1\tMEAN LATENCY\tPROMPT TOKENS\tCOMPLETION TOKENS\tERRORS")
+	for _, variant := range result.Variants {
+		errorCount := len(variant.Errors)
+		for _, c := range variant.Cases {
+			if c.Error != nil {
+				errorCount++
+			}
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%.2f\t%.2f\t%.2f\t%s\t%d\t%d\t%d\n",
+			variant.Name,
+			variant.Model,
+			variant.Overall.Precision,
+			variant.Overall.Recall,
+			variant.Overall.F1,
+			time.Duration(variant.MeanLatencyMs*float64(time.Millisecond)).Round(time.Millisecond),
+			variant.PromptTokens,
+			variant.CompletionTokens,
+			errorCount,
+		)
+	}
+	_ = w.Flush()
+}
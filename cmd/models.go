@@ -0,0 +1,317 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/ollama/ollama/api"
+	"github.com/spf13/cobra"
+)
+
+// verifyKeepAlive is how long a model loaded by `models verify` stays loaded, matching the Ollama default.
+const verifyKeepAlive = 5 * time.Minute
+
+// InitModelsCommand initializes the models command for the webassess CLI. Its subcommands manage the models installed
+// in Ollama and compare them against the allowed models. They connect to Ollama, starting it if needed, but skip the
+// checks and downloads of the assessment model run by the root command.
+func (a *WebAssess) InitModelsCommand() {
+	modelsCmd := &cobra.Command{
+		Use:   "models",
+		Short: "Manage the models installed in Ollama",
+		Long:  `List, pull, import, remove, inspect and verify the models installed in Ollama against the allowed models`,
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			if err := a.loadConfig(cmd); err != nil {
+				return err
+			}
+			a.applyOverallTimeout(cmd)
+			if err := a.configureOutput(cmd); err != nil {
+				return err
+			}
+			return a.connectOllama(cmd)
+		},
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the installed and allowed models",
+		Long:  `List the models installed in Ollama along with the allowed models that are not installed, with their sizes, digests and context windows`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			installed, err := ollama.GetAvailableOllamaModels(cmd.Context(), a.RootFlags.OllamaURL)
+			if err != nil {
+				return fmt.Errorf("failed to list models: %v", err)
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintln(writer, "NAME\tINSTALLED\tALLOWED\tSIZE\tDIGEST\tCONTEXT")
+			listed := map[string]bool{}
+			for _, model := range installed {
+				listed[model.Name] = true
+				window := "-"
+				if details, err := ollama.ShowModel(cmd.Context(), client, model.Name); err == nil && details.ContextWindowSize > 0 {
+					window = fmt.Sprintf("%d", details.ContextWindowSize)
+				}
+				fmt.Fprintf(writer, "%s\tyes\t%s\t%s\t%s\t%s\n", model.Name, a.allowedLabel(model), formatSize(model.Size), shortDigest(model.Digest), window)
+			}
+			allowed := append(ollama.Allowlist{}, a.Config.Ollama.AllowedModels...)
+			sort.Slice(allowed, func(i, j int) bool { return allowed[i].Name < allowed[j].Name })
+			for _, model := range allowed {
+				if !listed[model.Name] {
+					fmt.Fprintf(writer, "%s\tno\t%s\t-\t-\t-\n", model.Name, a.allowedLabel(ollama.Model{Name: model.Name}))
+				}
+			}
+			return writer.Flush()
+		},
+	}
+
+	pullCmd := &cobra.Command{
+		Use:   "pull MODEL...",
+		Short: "Pull allowed models from the Ollama library",
+		Long:  `Pull models from the Ollama library. Only models in the allowed models list can be pulled.`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			for _, name := range args {
+				if !a.Config.Ollama.AllowedModels.IsAllowed(name) {
+					return fmt.Errorf("ollama model '%s' is not in the allowed models list", name)
+				}
+			}
+			for _, name := range args {
+				if err := ollama.PullModel(cmd.Context(), client, name, a.progressReporter(cmd.Context())); err != nil {
+					return err
+				}
+				// A pinned tag may have been re-pointed since it was approved
+				model, err := ollama.GetModel(cmd.Context(), a.RootFlags.OllamaURL, name)
+				if err != nil {
+					return fmt.Errorf("failed to get ollama model '%s' after pulling it: %v", name, err)
+				}
+				if err := a.Config.Ollama.AllowedModels.VerifyDigest(model); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Pulled %s\n", name)
+			}
+			return nil
+		},
+	}
+
+	importCmd := &cobra.Command{
+		Use:   "import NAME PATH",
+		Short: "Create an allowed model from a local GGUF file or Modelfile",
+		Long:  `Create a model in Ollama from a local GGUF file or Modelfile without downloading anything, apply the webassess system prompt and the configured generation parameters, and register it as an allowed model pinned to its digest`,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.Config.Ollama.ImportedModelsFile == "" {
+				return errors.New("imported models cannot be registered because ollama.importedModelsFile is not set")
+			}
+			systemFile, err := cmd.Flags().GetString("system-file")
+			if err != nil {
+				return err
+			}
+			quantize, err := cmd.Flags().GetString("quantize")
+			if err != nil {
+				return err
+			}
+			system := ollama.DefaultSystemPrompt
+			if systemFile != "" {
+				data, err := os.ReadFile(systemFile)
+				if err != nil {
+					return fmt.Errorf("failed to read system prompt: %v", err)
+				}
+				system = strings.TrimSpace(string(data))
+			}
+
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			name := ollama.QualifiedModelName(args[0])
+			options := ollama.ImportOptions{System: system, Parameters: a.Config.Generation, Quantize: quantize}
+			if err := ollama.ImportModel(cmd.Context(), client, name, args[1], options, a.progressReporter(cmd.Context())); err != nil {
+				return err
+			}
+
+			model, err := ollama.GetModel(cmd.Context(), a.RootFlags.OllamaURL, name)
+			if err != nil {
+				return fmt.Errorf("failed to get ollama model '%s' after importing it: %v", name, err)
+			}
+			if err := ollama.RegisterImportedModel(a.Config.Ollama.ImportedModelsFile, ollama.AllowedModel{Name: name, Digest: model.Digest}); err != nil {
+				return err
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Imported %s (%s) and registered it as an allowed model\n", name, shortDigest(model.Digest))
+			return nil
+		},
+	}
+	importCmd.Flags().String("system-file", "", "Path to a file with the system prompt to apply instead of the built-in webassess system prompt")
+	importCmd.Flags().String("quantize", "", "Quantize the model to this type, such as q4_K_M, when importing an unquantized model")
+
+	rmCmd := &cobra.Command{
+		Use:   "rm MODEL...",
+		Short: "Remove installed models",
+		Long:  `Remove models installed in Ollama`,
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			for _, name := range args {
+				if err := ollama.RemoveModel(cmd.Context(), client, name); err != nil {
+					return err
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "Removed %s\n", name)
+			}
+			return nil
+		},
+	}
+
+	infoCmd := &cobra.Command{
+		Use:   "info [MODEL]",
+		Short: "Show the details of an installed model",
+		Long:  `Show the details of an installed model, defaulting to the model selected with --ollama-model`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			details, err := ollama.ShowModel(cmd.Context(), client, a.modelArgument(args))
+			if err != nil {
+				return err
+			}
+
+			writer := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			fmt.Fprintf(writer, "Name:\t%s\n", details.Name)
+			fmt.Fprintf(writer, "Allowed:\t%s\n", a.allowedLabel(details.Model))
+			fmt.Fprintf(writer, "Digest:\t%s\n", details.Digest)
+			if allowed, ok := a.Config.Ollama.AllowedModels.Find(details.Name); ok && allowed.Digest != "" {
+				fmt.Fprintf(writer, "Pinned digest:\t%s\n", allowed.Digest)
+			}
+			fmt.Fprintf(writer, "Size:\t%s\n", formatSize(details.Size))
+			fmt.Fprintf(writer, "Modified:\t%s\n", details.ModifiedAt)
+			fmt.Fprintf(writer, "Family:\t%s\n", details.Family)
+			fmt.Fprintf(writer, "Parameters:\t%s\n", details.ParameterSize)
+			fmt.Fprintf(writer, "Quantization:\t%s\n", details.QuantizationLevel)
+			fmt.Fprintf(writer, "Format:\t%s\n", details.Format)
+			fmt.Fprintf(writer, "Context window:\t%s\n", formatContextWindow(details.ContextWindowSize))
+			names := make([]string, 0, len(details.Parameters))
+			for name := range details.Parameters {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			for _, name := range names {
+				fmt.Fprintf(writer, "Parameter %s:\t%s\n", name, details.Parameters[name])
+			}
+			if details.System != "" {
+				fmt.Fprintf(writer, "System prompt:\t%s\n", strings.ReplaceAll(details.System, "\n", " "))
+			}
+			return writer.Flush()
+		},
+	}
+
+	verifyCmd := &cobra.Command{
+		Use:   "verify [MODEL]",
+		Short: "Verify that a model is installed, allowed and loads",
+		Long:  `Verify that a model is installed, is in the allowed models list, matches its pinned digest and can be loaded by Ollama, defaulting to the model selected with --ollama-model`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			name := a.modelArgument(args)
+			out := cmd.OutOrStdout()
+			fmt.Fprintln(out, name)
+
+			failed := false
+			check := func(label string, err error) {
+				if err != nil {
+					failed = true
+					fmt.Fprintf(out, "  FAIL  %s: %v\n", label, err)
+					return
+				}
+				fmt.Fprintf(out, "  ok    %s\n", label)
+			}
+
+			details, err := ollama.ShowModel(cmd.Context(), client, name)
+			check("installed", err)
+			var allowedErr error
+			if !a.Config.Ollama.AllowedModels.IsAllowed(name) {
+				allowedErr = errors.New("not in the allowed models list")
+			}
+			check("allowed", allowedErr)
+			if err == nil {
+				check("digest", a.Config.Ollama.AllowedModels.VerifyDigest(details.Model))
+				check("loads", ollama.WarmModel(cmd.Context(), client, details.Model, verifyKeepAlive))
+			}
+
+			if failed {
+				return fmt.Errorf("model '%s' failed verification", name)
+			}
+			return nil
+		},
+	}
+
+	modelsCmd.AddCommand(listCmd)
+	modelsCmd.AddCommand(pullCmd)
+	modelsCmd.AddCommand(importCmd)
+	modelsCmd.AddCommand(rmCmd)
+	modelsCmd.AddCommand(infoCmd)
+	modelsCmd.AddCommand(verifyCmd)
+	a.RootCmd.AddCommand(modelsCmd)
+}
+
+// allowedLabel describes whether the model is allowed, and whether its installed digest matches the pinned digest.
+func (a *WebAssess) allowedLabel(model ollama.Model) string {
+	allowed, ok := a.Config.Ollama.AllowedModels.Find(model.Name)
+	switch {
+	case !ok:
+		return "no"
+	case allowed.Digest == "":
+		return "yes"
+	case model.Digest == "":
+		return "pinned"
+	case a.Config.Ollama.AllowedModels.VerifyDigest(model) != nil:
+		return "digest mismatch"
+	default:
+		return "pinned"
+	}
+}
+
+// modelArgument returns the model named on the command line, or the model selected with --ollama-model.
+func (a *WebAssess) modelArgument(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return a.Config.Ollama.Model
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func formatContextWindow(size int) string {
+	if size <= 0 {
+		return "unknown"
+	}
+	return fmt.Sprintf("%d tokens", size)
+}
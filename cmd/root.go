@@ -4,6 +4,7 @@
 package cmd
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"os"
@@ -13,8 +14,11 @@ import (
 
 	"github.com/Method-Security/pkg/signal"
 	"github.com/Method-Security/pkg/writer"
+	webassess "github.com/Method-Security/webassess/generated/go"
 	"github.com/Method-Security/webassess/internal/config"
 	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/progress"
+	"github.com/Method-Security/webassess/internal/report"
 	"github.com/palantir/pkg/datetime"
 	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 	"github.com/spf13/cobra"
@@ -26,12 +30,31 @@ import (
 type WebAssess struct {
 	Version      string
 	RootFlags    config.RootFlags
+	Config       config.Config
+	ConfigPath   string
 	OutputConfig writer.OutputConfig
 	OutputSignal signal.Signal
-	RootCmd      *cobra.Command
-	VersionCmd   *cobra.Command
+	ExitCode     int
+	// OllamaProcess is the Ollama server spawned by the CLI, or nil if an instance was already running.
+	OllamaProcess *ollama.Process
+	RootCmd       *cobra.Command
+	VersionCmd    *cobra.Command
+
+	cancelOverallTimeout context.CancelFunc
 }
 
+// Exit codes returned by the CLI. ExitCodeToolFailure is returned when the CLI could not run or, with --fail-on-error,
+// when an assessment recorded an error. ExitCodeFindings is returned when --fail-on is set and a finding at or above
+// the threshold was reported.
+const (
+	ExitCodeSuccess     = 0
+	ExitCodeToolFailure = 1
+	ExitCodeFindings    = 2
+)
+
+// ollamaStopTimeout is how long a spawned Ollama server has to shut down before it is killed.
+const ollamaStopTimeout = 10 * time.Second
+
 // NewWebAssess creates a new webassess struct with the provided version string. The webassess struct is used throughout the
 // subcommands as a contex within which output results and configuration values can be stored.
 // We pass the version value in from the main.go file, where we set the version string during the build process.
@@ -44,6 +67,7 @@ func NewWebAssess(version string) *WebAssess {
 			OllamaURL:   "http://127.0.0.1:11434",
 			OllamaModel: ollama.Model{},
 		},
+		Config:       config.DefaultConfig(),
 		OutputConfig: writer.NewOutputConfig(nil, writer.NewFormat(writer.SIGNAL)),
 		OutputSignal: signal.NewSignal(nil, datetime.DateTime(time.Now()), nil, 0, nil),
 	}
@@ -58,57 +82,27 @@ func NewWebAssess(version string) *WebAssess {
 // are used to set up the output configuration and signal before the command is run, and to write the output signal after the
 // command has completed.
 func (a *WebAssess) InitRootCommand() {
-	var outputFormat string
-	var outputFile string
 	a.RootCmd = &cobra.Command{
 		Use:   "webassess",
 		Short: "Perform an assessment of a security resource with AI at the edge",
 		Long:  `Perform an assessment of a security resource with AI at the edge`,
 		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
-			logger := svc1log.FromContext(cmd.Context())
-			// Attempt to get Ollama URL from param, otherwise check that it is locally installed
-			// and if it is not locally running, attempt to start ollama
-			ollamaURL, err := cmd.Flags().GetString("ollama-url")
-			if ollamaURL == "" || err != nil {
-				// Check for ollama in the path
-				_, pathErr := exec.LookPath("ollama")
-				if pathErr != nil {
-					a.OutputSignal.AddError(errors.New("ollama is not installed or is not in the system path"))
-					return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-				}
-
-				// Check to see if ollama is running on the standard URL without being spawned by the CLI
-				if !ollama.IsOllamaRunning(ollama.OllamaStandardBaseURL) {
-					logger.Info("ollama not running on default port, attempting to start ollama...")
-					err := ollama.StartOllama()
-					if err != nil {
-						a.OutputSignal.AddError(errors.New("failed to start ollama:" + err.Error()))
-						return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-					}
-
-					// Check to see if ollama is running after attempting to start it
-					if !ollama.IsOllamaRunning(ollama.OllamaStandardBaseURL) {
-						a.OutputSignal.AddError(errors.New("ollama could not be started by the CLI"))
-						return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-					}
-				}
-				ollamaURL = ollama.OllamaStandardBaseURL
-			} else {
-				// Check to see if ollama is running on the provided URL
-				if !ollama.IsOllamaRunning(ollamaURL) {
-					a.OutputSignal.AddError(errors.New("ollama is not running on the provided URL"))
-					return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-				}
+			if err := a.loadConfig(cmd); err != nil {
+				return err
 			}
-			a.RootFlags.OllamaURL = ollamaURL
-
-			// Set OLLAMA_HOST environment variable for ollama client to pick up
-			if err := os.Setenv("OLLAMA_HOST", ollamaURL); err != nil {
-				a.OutputSignal.AddError(errors.New("failed to set OLLAMA_HOST environment variable: " + err.Error()))
-				return fmt.Errorf(*a.OutputSignal.ErrorMessage)
+			if err := a.validateExitPolicy(); err != nil {
+				return err
+			}
+			a.applyOverallTimeout(cmd)
+			// Configure the logger first, so that starting Ollama and downloading models is logged
+			if err := a.configureOutput(cmd); err != nil {
+				return err
+			}
+			if err := a.connectOllama(cmd); err != nil {
+				return err
 			}
 
-			// Check to see if the target ollama model is available
+			// Resolve the assessment model, downloading it first if it is missing and downloads are allowed
 			allowDownload, err := cmd.Flags().GetBool("allow-download")
 			if err != nil {
 				a.OutputSignal.AddError(err)
@@ -120,55 +114,33 @@ func (a *WebAssess) InitRootCommand() {
 				return fmt.Errorf(*a.OutputSignal.ErrorMessage)
 			}
 
-			if !ollama.ModelReady(ollamaURL, ollamaModel) {
-				if allowDownload {
-					// Download the model only if in allowed list
-					if !ollama.IsAllowedModel(ollamaModel) {
-						a.OutputSignal.AddError(fmt.Errorf("ollama model '%s' is not in the allowed models list", ollamaModel))
-						return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-					}
-					err := ollama.DownloadOllamaModel(ollamaURL, ollamaModel)
-					if err != nil {
-						a.OutputSignal.AddError(errors.New("failed to download ollama model: " + err.Error()))
-						return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-					}
-					// Check if model is ready after downloading
-					if !ollama.ModelReady(ollamaURL, ollamaModel) {
-						a.OutputSignal.AddError(errors.New("ollama model is not ready after download"))
-						return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-					}
-				} else {
-					// Exit since model is not available
-					a.OutputSignal.AddError(fmt.Errorf("ollama model '%s' is not available and allow-download is not set", ollamaModel))
-					return fmt.Errorf(*a.OutputSignal.ErrorMessage)
-				}
-			}
-
-			// Get model and set it in the root flags
-			model, err := ollama.GetModel(ollamaURL, ollamaModel)
+			model, err := a.resolveModel(cmd.Context(), ollamaModel, allowDownload)
 			if err != nil {
-				a.OutputSignal.AddError(errors.New("failed to get ollama model: " + err.Error()))
+				a.OutputSignal.AddError(err)
 				return fmt.Errorf(*a.OutputSignal.ErrorMessage)
 			}
 			a.RootFlags.OllamaModel = model
-
-			format, err := validateOutputFormat(outputFormat)
-			if err != nil {
-				return err
-			}
-			var outputFilePointer *string
-			if outputFile != "" {
-				outputFilePointer = &outputFile
-			} else {
-				outputFilePointer = nil
-			}
-			a.OutputConfig = writer.NewOutputConfig(outputFilePointer, format)
-			cmd.SetContext(svc1log.WithLogger(cmd.Context(), config.InitializeLogging(cmd, &a.RootFlags)))
 			return nil
 		},
 		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
 			completedAt := datetime.DateTime(time.Now())
 			a.OutputSignal.CompletedAt = &completedAt
+			a.ExitCode = a.evaluateExitPolicy()
+			if report.IsFormat(a.OutputConfig.Output.String()) {
+				return report.Write(
+					a.OutputSignal.Content,
+					report.Format(a.OutputConfig.Output.String()),
+					a.OutputConfig.FilePath,
+					report.Metadata{
+						ToolVersion:  a.Version,
+						Model:        a.RootFlags.OllamaModel,
+						StartedAt:    a.OutputSignal.StartedAt,
+						CompletedAt:  a.OutputSignal.CompletedAt,
+						Status:       a.OutputSignal.Status,
+						ErrorMessage: a.OutputSignal.ErrorMessage,
+					},
+				)
+			}
 			return writer.Write(
 				a.OutputSignal.Content,
 				a.OutputConfig,
@@ -180,13 +152,21 @@ func (a *WebAssess) InitRootCommand() {
 		},
 	}
 
+	a.RootCmd.PersistentFlags().String("config", "", "Path to a YAML or TOML configuration file")
+	a.RootCmd.PersistentFlags().String("profile", "", "Named assessment profile to apply from the configuration file or the built-in profiles")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.RootFlags.Quiet, "quiet", "q", false, "Suppress output")
 	a.RootCmd.PersistentFlags().BoolVarP(&a.RootFlags.Verbose, "verbose", "v", false, "Verbose output")
 	a.RootCmd.PersistentFlags().StringP("ollama-url", "u", "", "URL for Ollama service")
 	a.RootCmd.PersistentFlags().StringP("ollama-model", "m", "qwen2.5:0.5b", "Ollama model and version to use for assessment")
 	a.RootCmd.PersistentFlags().BoolP("allow-download", "d", false, "Allow downloading of models from internet if not already available")
-	a.RootCmd.PersistentFlags().StringVarP(&outputFile, "output-file", "f", "", "Path to output file. If blank, will output to STDOUT")
-	a.RootCmd.PersistentFlags().StringVarP(&outputFormat, "output", "o", "signal", "Output format (signal, json, yaml). Default value is signal")
+	a.RootCmd.PersistentFlags().Bool("keep-ollama", false, "Leave the Ollama server started by the CLI running after it exits")
+	a.RootCmd.PersistentFlags().StringVar(&a.RootFlags.FailOn, "fail-on", "", "Exit with code 2 if a finding at or above this severity is reported (critical, high, medium, low, info)")
+	a.RootCmd.PersistentFlags().BoolVar(&a.RootFlags.FailOnError, "fail-on-error", false, "Exit with code 1 if an assessment recorded an error")
+	a.RootCmd.PersistentFlags().Bool("no-cache", false, "Do not read or store model responses in the on-disk cache")
+	a.RootCmd.PersistentFlags().Duration("timeout", 0, "Maximum time spent fetching and analyzing each target, or 0 for no limit")
+	a.RootCmd.PersistentFlags().Duration("overall-timeout", 0, "Maximum time the whole command may run, or 0 for no limit")
+	a.RootCmd.PersistentFlags().StringP("output-file", "f", "", "Path to output file. If blank, will output to STDOUT")
+	a.RootCmd.PersistentFlags().StringP("output", "o", "signal", "Output format (signal, json, yaml, sarif, markdown, html). Default value is signal")
 
 	a.VersionCmd = &cobra.Command{
 		Use:   "version",
@@ -204,6 +184,204 @@ func (a *WebAssess) InitRootCommand() {
 	a.RootCmd.AddCommand(a.VersionCmd)
 }
 
+// connectOllama resolves the Ollama instance used by the command: the one at --ollama-url, or otherwise the one on the
+// standard URL, which is spawned if it is not already running. OLLAMA_HOST is set for the Ollama API client.
+func (a *WebAssess) connectOllama(cmd *cobra.Command) error {
+	logger := svc1log.FromContext(cmd.Context())
+
+	// Attempt to get Ollama URL from param, otherwise check that it is locally installed
+	// and if it is not locally running, attempt to start ollama
+	ollamaURL, err := cmd.Flags().GetString("ollama-url")
+	if ollamaURL == "" || err != nil {
+		// Check for ollama in the path
+		_, pathErr := exec.LookPath("ollama")
+		if pathErr != nil {
+			a.OutputSignal.AddError(errors.New("ollama is not installed or is not in the system path"))
+			return fmt.Errorf(*a.OutputSignal.ErrorMessage)
+		}
+
+		// Reuse an instance that is already running on the standard URL, and only spawn one otherwise
+		if !ollama.IsOllamaRunning(cmd.Context(), ollama.OllamaStandardBaseURL) {
+			logger.Info("ollama not running on default port, attempting to start ollama...")
+			process, err := ollama.StartOllama(cmd.Context(), ollama.ProcessOptions{
+				LogFile:      a.Config.Ollama.LogFile,
+				ReadyTimeout: time.Duration(a.Config.Ollama.StartTimeout),
+			})
+			if err != nil {
+				a.OutputSignal.AddError(errors.New("failed to start ollama:" + err.Error()))
+				return fmt.Errorf(*a.OutputSignal.ErrorMessage)
+			}
+			a.OllamaProcess = process
+		}
+		ollamaURL = ollama.OllamaStandardBaseURL
+	} else {
+		// Check to see if ollama is running on the provided URL
+		if !ollama.IsOllamaRunning(cmd.Context(), ollamaURL) {
+			if err := cmd.Context().Err(); err != nil {
+				a.OutputSignal.AddError(fmt.Errorf("stopped before connecting to ollama: %v", err))
+				return fmt.Errorf(*a.OutputSignal.ErrorMessage)
+			}
+			a.OutputSignal.AddError(errors.New("ollama is not running on the provided URL"))
+			return fmt.Errorf(*a.OutputSignal.ErrorMessage)
+		}
+	}
+	a.RootFlags.OllamaURL = ollamaURL
+
+	// Set OLLAMA_HOST environment variable for ollama client to pick up
+	if err := os.Setenv("OLLAMA_HOST", ollamaURL); err != nil {
+		a.OutputSignal.AddError(errors.New("failed to set OLLAMA_HOST environment variable: " + err.Error()))
+		return fmt.Errorf(*a.OutputSignal.ErrorMessage)
+	}
+	return nil
+}
+
+// StopOllama stops the Ollama server spawned by the CLI when it exits, unless --keep-ollama was given. An instance that
+// was already running is never stopped.
+func (a *WebAssess) StopOllama(ctx context.Context) {
+	if a.OllamaProcess == nil {
+		return
+	}
+	logger := svc1log.FromContext(ctx)
+	if a.Config.Ollama.Keep {
+		logger.Info("Leaving ollama running", svc1log.SafeParam("pid", a.OllamaProcess.Pid()), svc1log.SafeParam("url", a.OllamaProcess.URL))
+		a.OllamaProcess.Detach()
+		return
+	}
+	logger.Debug("Stopping ollama", svc1log.SafeParam("pid", a.OllamaProcess.Pid()))
+	if err := a.OllamaProcess.Stop(ollamaStopTimeout); err != nil {
+		logger.Warn("Failed to stop ollama", svc1log.Stacktrace(err))
+	}
+}
+
+// loadConfig resolves the effective configuration from the configuration file, the environment and the command line
+// flags, and updates any flags that were not explicitly set with their configured values.
+func (a *WebAssess) loadConfig(cmd *cobra.Command) error {
+	path, err := cmd.Flags().GetString("config")
+	if err != nil {
+		return err
+	}
+	profile, err := cmd.Flags().GetString("profile")
+	if err != nil {
+		return err
+	}
+	cfg, loadedPath, err := config.LoadConfig(path, profile)
+	if err != nil {
+		return err
+	}
+	if err := config.ApplyFlags(&cfg, cmd.Flags()); err != nil {
+		return err
+	}
+	a.Config = cfg
+	a.ConfigPath = loadedPath
+	return nil
+}
+
+// configureOutput sets up the output configuration and logger from the output flags. It is called at the end of the
+// root PersistentPreRunE, and directly by subcommands that do not need a running Ollama instance.
+func (a *WebAssess) configureOutput(cmd *cobra.Command) error {
+	outputFormat, err := cmd.Flags().GetString("output")
+	if err != nil {
+		return err
+	}
+	outputFile, err := cmd.Flags().GetString("output-file")
+	if err != nil {
+		return err
+	}
+
+	format, err := validateOutputFormat(outputFormat)
+	if err != nil {
+		return err
+	}
+	var outputFilePointer *string
+	if outputFile != "" {
+		outputFilePointer = &outputFile
+	} else {
+		outputFilePointer = nil
+	}
+	a.OutputConfig = writer.NewOutputConfig(outputFilePointer, format)
+	cmd.SetContext(svc1log.WithLogger(cmd.Context(), config.InitializeLogging(cmd, &a.RootFlags)))
+	return nil
+}
+
+// resolveModel returns an installed model, after downloading it if it is missing and downloads are allowed, and
+// verifies it against its pinned digest before any assessment runs.
+func (a *WebAssess) resolveModel(ctx context.Context, name string, allowDownload bool) (ollama.Model, error) {
+	ollamaURL := a.RootFlags.OllamaURL
+	if !ollama.ModelReady(ctx, ollamaURL, name) {
+		if !allowDownload {
+			return ollama.Model{}, fmt.Errorf("ollama model '%s' is not available and allow-download is not set", name)
+		}
+		// Download the model only if in allowed list
+		if !a.Config.Ollama.AllowedModels.IsAllowed(name) {
+			return ollama.Model{}, fmt.Errorf("ollama model '%s' is not in the allowed models list", name)
+		}
+		if err := ollama.DownloadOllamaModel(ctx, name, ollamaURL, a.progressReporter(ctx)); err != nil {
+			return ollama.Model{}, errors.New("failed to download ollama model: " + err.Error())
+		}
+		if !ollama.ModelReady(ctx, ollamaURL, name) {
+			return ollama.Model{}, errors.New("ollama model is not ready after download")
+		}
+	}
+
+	model, err := ollama.GetModel(ctx, ollamaURL, name)
+	if err != nil {
+		return ollama.Model{}, errors.New("failed to get ollama model: " + err.Error())
+	}
+	if err := a.Config.Ollama.AllowedModels.VerifyDigest(model); err != nil {
+		return ollama.Model{}, err
+	}
+	return model, nil
+}
+
+// applyOverallTimeout bounds the context of the command by the overall timeout, if one is configured. When it elapses,
+// in-flight generations are cancelled and the work done so far is reported as timed out.
+func (a *WebAssess) applyOverallTimeout(cmd *cobra.Command) {
+	if a.Config.Timeouts.Overall <= 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(cmd.Context(), time.Duration(a.Config.Timeouts.Overall))
+	a.cancelOverallTimeout = cancel
+	cmd.SetContext(ctx)
+}
+
+// Close releases the resources held for the command once it has completed.
+func (a *WebAssess) Close() {
+	if a.cancelOverallTimeout != nil {
+		a.cancelOverallTimeout()
+	}
+}
+
+// progressReporter returns the reporter for the progress of model pulls and analyses, written to STDERR so that it
+// does not mix with the output. It renders a progress bar on a terminal, logs otherwise, and is nil under --quiet.
+func (a *WebAssess) progressReporter(ctx context.Context) progress.Reporter {
+	return progress.New(ctx, os.Stderr, a.RootFlags.Quiet)
+}
+
+func (a *WebAssess) validateExitPolicy() error {
+	if a.RootFlags.FailOn == "" {
+		return nil
+	}
+	if _, err := webassess.NewSeverityFromString(strings.ToUpper(a.RootFlags.FailOn)); err != nil {
+		return errors.New("invalid fail-on severity. Valid severities are: critical, high, medium, low, info")
+	}
+	return nil
+}
+
+// evaluateExitPolicy determines the exit code of the CLI from the output signal. Findings at or above the --fail-on
+// threshold take precedence over errors, as they are the more specific signal for a pipeline to act on.
+func (a *WebAssess) evaluateExitPolicy() int {
+	if a.RootFlags.FailOn != "" {
+		threshold, err := webassess.NewSeverityFromString(strings.ToUpper(a.RootFlags.FailOn))
+		if err == nil && report.HasFindingAtOrAbove(a.OutputSignal.Content, threshold) {
+			return ExitCodeFindings
+		}
+	}
+	if a.RootFlags.FailOnError && (a.OutputSignal.Status != 0 || report.HasErrors(a.OutputSignal.Content)) {
+		return ExitCodeToolFailure
+	}
+	return ExitCodeSuccess
+}
+
 func validateOutputFormat(output string) (writer.Format, error) {
 	var format writer.FormatValue
 	switch strings.ToLower(output) {
@@ -214,7 +392,12 @@ func validateOutputFormat(output string) (writer.Format, error) {
 	case "signal":
 		format = writer.SIGNAL
 	default:
-		return writer.Format{}, errors.New("invalid output format. Valid formats are: json, yaml, signal")
+		// Formats that the writer package does not know about are rendered by the report package instead
+		if report.IsFormat(output) {
+			format = writer.FormatValue(strings.ToLower(output))
+			break
+		}
+		return writer.Format{}, errors.New("invalid output format. Valid formats are: json, yaml, signal, sarif, markdown, html")
 	}
 	return writer.NewFormat(format), nil
 }
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/server"
+	"github.com/ollama/ollama/api"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+	"github.com/spf13/cobra"
+)
+
+// InitServeCommand initializes the serve command for the webassess CLI. This command runs a long-lived HTTP server
+// that accepts assessments as jobs, so that the Ollama checks and model loading are done once rather than for every
+// assessment. Reports are returned in the same shapes written by the url command.
+func (a *WebAssess) InitServeCommand() {
+	serveCmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Serve assessments over an HTTP API",
+		Long:  `Serve URL and file content assessments over an HTTP API backed by a job queue`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			log := svc1log.FromContext(ctx)
+
+			options, err := a.assessmentOptions(ctx)
+			if err != nil {
+				return err
+			}
+			if err := a.resolveAnalysisModels(ctx, &options); err != nil {
+				return err
+			}
+			keepAlive := time.Duration(a.Config.Serve.KeepAlive)
+			options.KeepAlive = &keepAlive
+			// Jobs run concurrently and are followed through the API, so their progress is not reported
+			options.Progress = nil
+
+			client, err := api.ClientFromEnvironment()
+			if err != nil {
+				return fmt.Errorf("failed to create Ollama client: %v", err)
+			}
+			log.Info("Loading model", svc1log.SafeParam("model", a.RootFlags.OllamaModel.Name))
+			if err := ollama.WarmModel(ctx, client, a.RootFlags.OllamaModel, keepAlive); err != nil {
+				return err
+			}
+
+			srv := server.New(a.RootFlags.OllamaModel, options, server.Options{
+				Address:         a.Config.Serve.Address,
+				Concurrency:     a.Config.Serve.Concurrency,
+				QueueSize:       a.Config.Serve.QueueSize,
+				JobRetention:    time.Duration(a.Config.Serve.JobRetention),
+				ShutdownTimeout: time.Duration(a.Config.Serve.ShutdownTimeout),
+				ToolVersion:     a.Version,
+			})
+			return srv.Run(ctx)
+		},
+		// The server returns reports over HTTP, so there is no output signal to write
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+
+	serveCmd.Flags().String("address", "127.0.0.1:8080", "Address to listen on")
+	serveCmd.Flags().Int("concurrency", 1, "Number of assessments to run at the same time")
+	serveCmd.Flags().Int("queue-size", 100, "Number of jobs that may wait in the queue before submissions are rejected")
+	serveCmd.Flags().Duration("job-retention", time.Hour, "How long finished jobs are kept, or 0 to keep them forever")
+	serveCmd.Flags().Duration("shutdown-timeout", 30*time.Second, "How long running jobs may take to complete on shutdown before they are cancelled")
+	serveCmd.Flags().Duration("keep-alive", -1, "How long the model stays loaded after the last request, or a negative value to keep it loaded")
+
+	a.RootCmd.AddCommand(serveCmd)
+}
@@ -1,7 +1,18 @@
 package cmd
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/cache"
+	"github.com/Method-Security/webassess/internal/diff"
+	"github.com/Method-Security/webassess/internal/report"
 	"github.com/Method-Security/webassess/internal/url"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 	"github.com/spf13/cobra"
 )
 
@@ -12,6 +23,16 @@ func (a *WebAssess) InitURLAssess() {
 		Use:   "url",
 		Short: "Perform a URL content assessment against a URL target",
 		Long:  `Perform a URL content assessment against a URL target`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				return err
+			}
+			if dryRun && report.IsFormat(a.OutputConfig.Output.String()) {
+				return errors.New("--dry-run only supports the signal, json and yaml output formats")
+			}
+			return nil
+		},
 		Run: func(cmd *cobra.Command, args []string) {
 			target, err := cmd.Flags().GetString("target")
 			if err != nil {
@@ -21,13 +42,195 @@ func (a *WebAssess) InitURLAssess() {
 				return
 			}
 
-			report := url.PerformURLAssess(cmd.Context(), target, a.RootFlags.OllamaModel)
+			modelVerification, err := cmd.Flags().GetBool("model-verification")
+			if err != nil {
+				errorMessage := err.Error()
+				a.OutputSignal.ErrorMessage = &errorMessage
+				a.OutputSignal.Status = 1
+				return
+			}
+
+			dryRun, err := cmd.Flags().GetBool("dry-run")
+			if err != nil {
+				errorMessage := err.Error()
+				a.OutputSignal.ErrorMessage = &errorMessage
+				a.OutputSignal.Status = 1
+				return
+			}
 
-			a.OutputSignal.Content = report
+			baselinePath, err := cmd.Flags().GetString("baseline")
+			if err != nil {
+				errorMessage := err.Error()
+				a.OutputSignal.ErrorMessage = &errorMessage
+				a.OutputSignal.Status = 1
+				return
+			}
+			var baseline []webassess.UrlReport
+			if baselinePath != "" {
+				baseline, err = diff.LoadReports(baselinePath)
+				if err != nil {
+					errorMessage := err.Error()
+					a.OutputSignal.ErrorMessage = &errorMessage
+					a.OutputSignal.Status = 1
+					return
+				}
+			}
+
+			options, err := a.assessmentOptions(cmd.Context())
+			if err != nil {
+				errorMessage := err.Error()
+				a.OutputSignal.ErrorMessage = &errorMessage
+				a.OutputSignal.Status = 1
+				return
+			}
+			options.ModelVerification = modelVerification
+			if err := a.resolveAnalysisModels(cmd.Context(), &options); err != nil {
+				errorMessage := err.Error()
+				a.OutputSignal.ErrorMessage = &errorMessage
+				a.OutputSignal.Status = 1
+				return
+			}
+
+			if dryRun {
+				a.OutputSignal.Content = url.PlanURLAssess(cmd.Context(), target, a.RootFlags.OllamaModel, a.RootFlags.OllamaURL, options)
+				return
+			}
+
+			urlReport := url.PerformURLAssess(cmd.Context(), target, a.RootFlags.OllamaModel, options)
+
+			if baselinePath != "" {
+				urlReport.Diff = diff.CompareTarget(diff.FindTarget(baseline, target), urlReport)
+			}
+
+			a.OutputSignal.Content = urlReport
 		},
 	}
 
 	urlCmd.Flags().String("target", "", "URL target to perform web AI assessment against")
+	urlCmd.Flags().String("baseline", "", "Path to a previous JSON report to diff the findings against")
+	urlCmd.Flags().String("analysis-template", "", "Path to a Go text/template file used to render the analysis prompt")
+	urlCmd.Flags().String("synthesis-template", "", "Path to a Go text/template file used to render the synthesis prompt")
+	urlCmd.Flags().String("system-prompt", "", "Path to a file with the system message sent with the analysis and synthesis prompts")
+	urlCmd.Flags().Bool("model-verification", true, "Ask the model to re-check findings whose evidence cannot be found in the content")
+	urlCmd.Flags().String("ensemble", "", "Comma-separated models that each analyze the target, with their findings merged and counted by agreement")
+	urlCmd.Flags().String("cascade-model", "", "Larger model that only analyzes the chunks flagged by the assessment model or the pattern scanners")
+	urlCmd.Flags().String("judge-model", "", "Model that adjudicates the findings the models of the ensemble disagree on")
+	urlCmd.Flags().Bool("dry-run", false, "Fetch the target and output the chunk plan, rendered prompts and token counts without calling the model")
 
 	a.RootCmd.AddCommand(urlCmd)
 }
+
+// assessmentOptions builds the assessment options from the effective configuration.
+func (a *WebAssess) assessmentOptions(ctx context.Context) (url.Options, error) {
+	chunkingStrategy, err := url.ParseChunkingStrategy(a.Config.Analysis.Chunking.Strategy)
+	if err != nil {
+		return url.Options{}, err
+	}
+	analysisTemplate, synthesisTemplate, err := a.Config.Prompts.Templates()
+	if err != nil {
+		return url.Options{}, err
+	}
+	prompts, err := url.NewPromptTemplates(analysisTemplate, synthesisTemplate)
+	if err != nil {
+		return url.Options{}, err
+	}
+	systemPrompt, err := a.Config.Prompts.SystemPrompt()
+	if err != nil {
+		return url.Options{}, err
+	}
+	examples := make([]url.AnalysisExample, 0, len(a.Config.Prompts.Examples))
+	for _, example := range a.Config.Prompts.Examples {
+		examples = append(examples, url.AnalysisExample{Content: example.Content, Answer: example.Answer})
+	}
+	prompts, err = prompts.WithSystem(systemPrompt, examples)
+	if err != nil {
+		return url.Options{}, err
+	}
+
+	return url.Options{
+		ModelVerification: a.Config.Analysis.ModelVerification,
+		Generation:        a.Config.Generation,
+		Fetch: url.FetchOptions{
+			Timeout:      time.Duration(a.Config.Fetch.Timeout),
+			UserAgent:    a.Config.Fetch.UserAgent,
+			MaxBodyBytes: a.Config.Fetch.MaxBodyBytes,
+			Headers:      a.Config.Fetch.Headers,
+		},
+		Chunking: url.ChunkingOptions{
+			Strategy: chunkingStrategy,
+			Size:     a.Config.Analysis.Chunking.Size,
+		},
+		Prompts:       prompts,
+		Hints:         a.Config.Prompts.Hints,
+		Cache:         a.openCache(ctx),
+		Progress:      a.progressReporter(ctx),
+		TargetTimeout: time.Duration(a.Config.Timeouts.Target),
+	}, nil
+}
+
+// resolveAnalysisModels resolves the models of the configured ensemble and its judge, or of the cascade, into the
+// options, downloading them if they are missing and downloads are allowed. An ensemble needs at least two models, a
+// judge needs an ensemble, and an ensemble cannot be combined with a cascade.
+func (a *WebAssess) resolveAnalysisModels(ctx context.Context, options *url.Options) error {
+	analysis := a.Config.Analysis
+	names := []string{}
+	for _, name := range analysis.Ensemble {
+		if !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	switch {
+	case len(names) == 1:
+		return fmt.Errorf("an ensemble needs at least two models, got %s", names[0])
+	case len(names) == 0 && analysis.JudgeModel != "":
+		return errors.New("a judge model requires an ensemble of models")
+	case len(names) > 0 && analysis.CascadeModel != "":
+		return errors.New("an ensemble cannot be combined with a cascade model")
+	}
+
+	for _, name := range names {
+		model, err := a.resolveModel(ctx, name, a.Config.Ollama.AllowDownload)
+		if err != nil {
+			return err
+		}
+		options.Ensemble.Models = append(options.Ensemble.Models, model)
+	}
+	if analysis.JudgeModel != "" {
+		judge, err := a.resolveModel(ctx, analysis.JudgeModel, a.Config.Ollama.AllowDownload)
+		if err != nil {
+			return err
+		}
+		options.Ensemble.Judge = &judge
+	}
+	if analysis.CascadeModel != "" {
+		cascade, err := a.resolveModel(ctx, analysis.CascadeModel, a.Config.Ollama.AllowDownload)
+		if err != nil {
+			return err
+		}
+		options.Cascade = &cascade
+	}
+	return nil
+}
+
+// cachePruneInterval is the minimum time between the prunes of the response cache at the start of a run.
+const cachePruneInterval = time.Hour
+
+// openCache opens the response cache if it is enabled, pruning expired entries and enforcing its size limit unless it
+// was pruned within cachePruneInterval. The cache is an optimization, so a cache that cannot be opened, for instance
+// because the data directory is not writable, is logged and the run continues without it.
+func (a *WebAssess) openCache(ctx context.Context) *cache.Cache {
+	if !a.Config.Cache.Enabled {
+		return nil
+	}
+	responseCache, err := cache.Open(a.Config.Cache.Dir, time.Duration(a.Config.Cache.TTL), a.Config.Cache.MaxBytes)
+	if err != nil {
+		svc1log.FromContext(ctx).Warn("Response cache is unavailable", svc1log.SafeParam("dir", a.Config.Cache.Dir), svc1log.Stacktrace(err))
+		return nil
+	}
+	// Pruning up front keeps the cache within its limits without a separate scheduled job, while pruning at most once
+	// per interval keeps frequent runs from walking the whole cache every time
+	if _, err := responseCache.PruneIfDue(cachePruneInterval); err != nil {
+		svc1log.FromContext(ctx).Warn("Failed to prune response cache", svc1log.SafeParam("dir", responseCache.Dir), svc1log.Stacktrace(err))
+	}
+	return responseCache
+}
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/watch"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+	"github.com/spf13/cobra"
+)
+
+// InitWatchCommand initializes the watch command for the webassess CLI. This command periodically checks a target
+// for changes to its page, scripts and security headers, only assesses the content that changed, and writes the
+// differences as a stream of JSON events.
+func (a *WebAssess) InitWatchCommand() {
+	watchCmd := &cobra.Command{
+		Use:   "watch",
+		Short: "Continuously monitor a URL target for changes",
+		Long:  `Periodically refetch a URL target and its scripts, assess the content that changed and output new and resolved findings, added and removed scripts and changed security headers`,
+		PreRunE: func(cmd *cobra.Command, _ []string) error {
+			switch strings.ToLower(a.OutputConfig.Output.String()) {
+			case "signal", "json":
+				return nil
+			default:
+				return errors.New("the watch command writes events as JSON lines and only supports the signal and json output formats")
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+
+			target, err := cmd.Flags().GetString("target")
+			if err != nil {
+				return err
+			}
+			maxChecks, err := cmd.Flags().GetInt("max-checks")
+			if err != nil {
+				return err
+			}
+
+			options, err := a.assessmentOptions(ctx)
+			if err != nil {
+				return err
+			}
+			if err := a.resolveAnalysisModels(ctx, &options); err != nil {
+				return err
+			}
+			watcher, err := watch.New(target, a.RootFlags.OllamaModel, options, watch.Options{
+				Interval:  time.Duration(a.Config.Watch.Interval),
+				MaxChecks: maxChecks,
+				StatePath: a.Config.Watch.StateFile,
+			})
+			if err != nil {
+				return err
+			}
+
+			var out io.Writer = os.Stdout
+			if a.OutputConfig.FilePath != nil {
+				file, err := os.OpenFile(*a.OutputConfig.FilePath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+				if err != nil {
+					return fmt.Errorf("failed to open output file: %v", err)
+				}
+				defer func() { _ = file.Close() }()
+				out = file
+			}
+
+			svc1log.FromContext(ctx).Info("Watching target", svc1log.SafeParam("target", target), svc1log.SafeParam("interval", time.Duration(a.Config.Watch.Interval).String()))
+			return watcher.Run(ctx, func(event webassess.WatchEvent) error {
+				data, err := json.Marshal(event)
+				if err != nil {
+					return err
+				}
+				_, err = out.Write(append(data, '\n'))
+				return err
+			})
+		},
+		// Events are written as they happen, so there is no output signal to write
+		PersistentPostRunE: func(cmd *cobra.Command, _ []string) error {
+			return nil
+		},
+	}
+
+	watchCmd.Flags().String("target", "", "URL target to monitor")
+	watchCmd.Flags().Duration("interval", time.Hour, "Time between checks of the target")
+	watchCmd.Flags().String("state-file", "", "Path to a file that keeps the state of the last check, so that a restarted watcher resumes instead of starting a new baseline")
+	watchCmd.Flags().Int("max-checks", 0, "Stop after this many checks, or 0 to run until interrupted")
+	_ = watchCmd.MarkFlagRequired("target")
+
+	a.RootCmd.AddCommand(watchCmd)
+}
@@ -6,14 +6,1388 @@ import (
 	json "encoding/json"
 	fmt "fmt"
 	core "github.com/Method-Security/webassess/generated/go/core"
+	time "time"
 )
 
+type AssessmentJob struct {
+	Id          string     `json:"id" url:"id"`
+	Status      JobStatus  `json:"status" url:"status"`
+	Target      string     `json:"target" url:"target"`
+	SubmittedAt time.Time  `json:"submittedAt" url:"submittedAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty" url:"startedAt,omitempty"`
+	CompletedAt *time.Time `json:"completedAt,omitempty" url:"completedAt,omitempty"`
+	Report      *UrlReport `json:"report,omitempty" url:"report,omitempty"`
+	Error       *string    `json:"error,omitempty" url:"error,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (a *AssessmentJob) GetExtraProperties() map[string]interface{} {
+	return a.extraProperties
+}
+
+func (a *AssessmentJob) UnmarshalJSON(data []byte) error {
+	type embed AssessmentJob
+	var unmarshaler = struct {
+		embed
+		SubmittedAt *core.DateTime `json:"submittedAt"`
+		StartedAt   *core.DateTime `json:"startedAt,omitempty"`
+		CompletedAt *core.DateTime `json:"completedAt,omitempty"`
+	}{
+		embed: embed(*a),
+	}
+	if err := json.Unmarshal(data, &unmarshaler); err != nil {
+		return err
+	}
+	*a = AssessmentJob(unmarshaler.embed)
+	a.SubmittedAt = unmarshaler.SubmittedAt.Time()
+	a.StartedAt = unmarshaler.StartedAt.TimePtr()
+	a.CompletedAt = unmarshaler.CompletedAt.TimePtr()
+
+	extraProperties, err := core.ExtractExtraProperties(data, *a)
+	if err != nil {
+		return err
+	}
+	a.extraProperties = extraProperties
+
+	a._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (a *AssessmentJob) MarshalJSON() ([]byte, error) {
+	type embed AssessmentJob
+	var marshaler = struct {
+		embed
+		SubmittedAt *core.DateTime `json:"submittedAt"`
+		StartedAt   *core.DateTime `json:"startedAt,omitempty"`
+		CompletedAt *core.DateTime `json:"completedAt,omitempty"`
+	}{
+		embed:       embed(*a),
+		SubmittedAt: core.NewDateTime(a.SubmittedAt),
+		StartedAt:   core.NewOptionalDateTime(a.StartedAt),
+		CompletedAt: core.NewOptionalDateTime(a.CompletedAt),
+	}
+	return json.Marshal(marshaler)
+}
+
+func (a *AssessmentJob) String() string {
+	if len(a._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(a._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(a); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", a)
+}
+
+type AssessmentJobList struct {
+	Jobs []*AssessmentJob `json:"jobs" url:"jobs"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (a *AssessmentJobList) GetExtraProperties() map[string]interface{} {
+	return a.extraProperties
+}
+
+func (a *AssessmentJobList) UnmarshalJSON(data []byte) error {
+	type unmarshaler AssessmentJobList
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*a = AssessmentJobList(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *a)
+	if err != nil {
+		return err
+	}
+	a.extraProperties = extraProperties
+
+	a._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (a *AssessmentJobList) String() string {
+	if len(a._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(a._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(a); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", a)
+}
+
+type AssessmentPlan struct {
+	Model                string           `json:"model" url:"model"`
+	Strategy             string           `json:"strategy" url:"strategy"`
+	ContextWindow        *int             `json:"contextWindow,omitempty" url:"contextWindow,omitempty"`
+	Prompts              []*PlannedPrompt `json:"prompts" url:"prompts"`
+	AnalysisGenerations  int              `json:"analysisGenerations" url:"analysisGenerations"`
+	SynthesisGenerations int              `json:"synthesisGenerations" url:"synthesisGenerations"`
+	EstimatedGenerations int              `json:"estimatedGenerations" url:"estimatedGenerations"`
+	TotalPromptTokens    int              `json:"totalPromptTokens" url:"totalPromptTokens"`
+	TokensEstimated      bool             `json:"tokensEstimated" url:"tokensEstimated"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (a *AssessmentPlan) GetExtraProperties() map[string]interface{} {
+	return a.extraProperties
+}
+
+func (a *AssessmentPlan) UnmarshalJSON(data []byte) error {
+	type unmarshaler AssessmentPlan
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*a = AssessmentPlan(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *a)
+	if err != nil {
+		return err
+	}
+	a.extraProperties = extraProperties
+
+	a._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (a *AssessmentPlan) String() string {
+	if len(a._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(a._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(a); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", a)
+}
+
+type AssessmentRequest struct {
+	Target            string  `json:"target" url:"target"`
+	Content           *string `json:"content,omitempty" url:"content,omitempty"`
+	ModelVerification *bool   `json:"modelVerification,omitempty" url:"modelVerification,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (a *AssessmentRequest) GetExtraProperties() map[string]interface{} {
+	return a.extraProperties
+}
+
+func (a *AssessmentRequest) UnmarshalJSON(data []byte) error {
+	type unmarshaler AssessmentRequest
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*a = AssessmentRequest(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *a)
+	if err != nil {
+		return err
+	}
+	a.extraProperties = extraProperties
+
+	a._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (a *AssessmentRequest) String() string {
+	if len(a._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(a._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(a); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", a)
+}
+
+type CascadeSummary struct {
+	ScreenModel       string           `json:"screenModel" url:"screenModel"`
+	EscalationModel   string           `json:"escalationModel" url:"escalationModel"`
+	Chunks            []*ScreenedChunk `json:"chunks" url:"chunks"`
+	EscalatedChunks   int              `json:"escalatedChunks" url:"escalatedChunks"`
+	ScreenMetrics     *UsageMetrics    `json:"screenMetrics,omitempty" url:"screenMetrics,omitempty"`
+	EscalationMetrics *UsageMetrics    `json:"escalationMetrics,omitempty" url:"escalationMetrics,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (c *CascadeSummary) GetExtraProperties() map[string]interface{} {
+	return c.extraProperties
+}
+
+func (c *CascadeSummary) UnmarshalJSON(data []byte) error {
+	type unmarshaler CascadeSummary
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*c = CascadeSummary(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *c)
+	if err != nil {
+		return err
+	}
+	c.extraProperties = extraProperties
+
+	c._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (c *CascadeSummary) String() string {
+	if len(c._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(c._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(c); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", c)
+}
+
+type DiffReport struct {
+	Baseline string        `json:"baseline" url:"baseline"`
+	Targets  []*TargetDiff `json:"targets" url:"targets"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (d *DiffReport) GetExtraProperties() map[string]interface{} {
+	return d.extraProperties
+}
+
+func (d *DiffReport) UnmarshalJSON(data []byte) error {
+	type unmarshaler DiffReport
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*d = DiffReport(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *d)
+	if err != nil {
+		return err
+	}
+	d.extraProperties = extraProperties
+
+	d._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (d *DiffReport) String() string {
+	if len(d._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(d._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(d); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", d)
+}
+
+type EnsembleMember struct {
+	Model    string        `json:"model" url:"model"`
+	Findings int           `json:"findings" url:"findings"`
+	Metrics  *UsageMetrics `json:"metrics,omitempty" url:"metrics,omitempty"`
+	Errors   []string      `json:"errors" url:"errors"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (e *EnsembleMember) GetExtraProperties() map[string]interface{} {
+	return e.extraProperties
+}
+
+func (e *EnsembleMember) UnmarshalJSON(data []byte) error {
+	type unmarshaler EnsembleMember
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = EnsembleMember(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *e)
+	if err != nil {
+		return err
+	}
+	e.extraProperties = extraProperties
+
+	e._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (e *EnsembleMember) String() string {
+	if len(e._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(e._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(e); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+type EnsembleSummary struct {
+	Members      []*EnsembleMember `json:"members" url:"members"`
+	JudgeModel   *string           `json:"judgeModel,omitempty" url:"judgeModel,omitempty"`
+	JudgeMetrics *UsageMetrics     `json:"judgeMetrics,omitempty" url:"judgeMetrics,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (e *EnsembleSummary) GetExtraProperties() map[string]interface{} {
+	return e.extraProperties
+}
+
+func (e *EnsembleSummary) UnmarshalJSON(data []byte) error {
+	type unmarshaler EnsembleSummary
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = EnsembleSummary(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *e)
+	if err != nil {
+		return err
+	}
+	e.extraProperties = extraProperties
+
+	e._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (e *EnsembleSummary) String() string {
+	if len(e._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(e._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(e); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+type EvalCaseResult struct {
+	Name             string     `json:"name" url:"name"`
+	File             string     `json:"file" url:"file"`
+	LatencyMs        int64      `json:"latencyMs" url:"latencyMs"`
+	PromptTokens     int        `json:"promptTokens" url:"promptTokens"`
+	CompletionTokens int        `json:"completionTokens" url:"completionTokens"`
+	TruePositives    int        `json:"truePositives" url:"truePositives"`
+	FalsePositives   int        `json:"falsePositives" url:"falsePositives"`
+	FalseNegatives   int        `json:"falseNegatives" url:"falseNegatives"`
+	Findings         []*Finding `json:"findings" url:"findings"`
+	Error            *string    `json:"error,omitempty" url:"error,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (e *EvalCaseResult) GetExtraProperties() map[string]interface{} {
+	return e.extraProperties
+}
+
+func (e *EvalCaseResult) UnmarshalJSON(data []byte) error {
+	type unmarshaler EvalCaseResult
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = EvalCaseResult(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *e)
+	if err != nil {
+		return err
+	}
+	e.extraProperties = extraProperties
+
+	e._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (e *EvalCaseResult) String() string {
+	if len(e._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(e._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(e); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+type EvalCategoryMetrics struct {
+	Category       *FindingCategory `json:"category,omitempty" url:"category,omitempty"`
+	TruePositives  int              `json:"truePositives" url:"truePositives"`
+	FalsePositives int              `json:"falsePositives" url:"falsePositives"`
+	FalseNegatives int              `json:"falseNegatives" url:"falseNegatives"`
+	Precision      float64          `json:"precision" url:"precision"`
+	Recall         float64          `json:"recall" url:"recall"`
+	F1             float64          `json:"f1" url:"f1"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (e *EvalCategoryMetrics) GetExtraProperties() map[string]interface{} {
+	return e.extraProperties
+}
+
+func (e *EvalCategoryMetrics) UnmarshalJSON(data []byte) error {
+	type unmarshaler EvalCategoryMetrics
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = EvalCategoryMetrics(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *e)
+	if err != nil {
+		return err
+	}
+	e.extraProperties = extraProperties
+
+	e._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (e *EvalCategoryMetrics) String() string {
+	if len(e._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(e._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(e); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+type EvalReport struct {
+	Corpus   string               `json:"corpus" url:"corpus"`
+	Status   *ReportStatus        `json:"status,omitempty" url:"status,omitempty"`
+	Variants []*EvalVariantResult `json:"variants" url:"variants"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (e *EvalReport) GetExtraProperties() map[string]interface{} {
+	return e.extraProperties
+}
+
+func (e *EvalReport) UnmarshalJSON(data []byte) error {
+	type unmarshaler EvalReport
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = EvalReport(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *e)
+	if err != nil {
+		return err
+	}
+	e.extraProperties = extraProperties
+
+	e._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (e *EvalReport) String() string {
+	if len(e._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(e._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(e); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+type EvalVariantResult struct {
+	Name              string                 `json:"name" url:"name"`
+	Model             string                 `json:"model" url:"model"`
+	AnalysisTemplate  *string                `json:"analysisTemplate,omitempty" url:"analysisTemplate,omitempty"`
+	SynthesisTemplate *string                `json:"synthesisTemplate,omitempty" url:"synthesisTemplate,omitempty"`
+	Overall           *EvalCategoryMetrics   `json:"overall" url:"overall"`
+	Categories        []*EvalCategoryMetrics `json:"categories" url:"categories"`
+	Cases             []*EvalCaseResult      `json:"cases" url:"cases"`
+	TotalLatencyMs    int64                  `json:"totalLatencyMs" url:"totalLatencyMs"`
+	MeanLatencyMs     float64                `json:"meanLatencyMs" url:"meanLatencyMs"`
+	PromptTokens      int                    `json:"promptTokens" url:"promptTokens"`
+	CompletionTokens  int                    `json:"completionTokens" url:"completionTokens"`
+	Errors            []string               `json:"errors" url:"errors"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (e *EvalVariantResult) GetExtraProperties() map[string]interface{} {
+	return e.extraProperties
+}
+
+func (e *EvalVariantResult) UnmarshalJSON(data []byte) error {
+	type unmarshaler EvalVariantResult
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*e = EvalVariantResult(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *e)
+	if err != nil {
+		return err
+	}
+	e.extraProperties = extraProperties
+
+	e._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (e *EvalVariantResult) String() string {
+	if len(e._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(e._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(e); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", e)
+}
+
+type FetchMetadata struct {
+	FinalUrl      string  `json:"finalUrl" url:"finalUrl"`
+	StatusCode    int     `json:"statusCode" url:"statusCode"`
+	ContentType   *string `json:"contentType,omitempty" url:"contentType,omitempty"`
+	ContentLength int     `json:"contentLength" url:"contentLength"`
+	ContentSha256 string  `json:"contentSha256" url:"contentSha256"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (f *FetchMetadata) GetExtraProperties() map[string]interface{} {
+	return f.extraProperties
+}
+
+func (f *FetchMetadata) UnmarshalJSON(data []byte) error {
+	type unmarshaler FetchMetadata
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*f = FetchMetadata(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *f)
+	if err != nil {
+		return err
+	}
+	f.extraProperties = extraProperties
+
+	f._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (f *FetchMetadata) String() string {
+	if len(f._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(f._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(f); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", f)
+}
+
+type Finding struct {
+	Title        string               `json:"title" url:"title"`
+	Category     FindingCategory      `json:"category" url:"category"`
+	Severity     Severity             `json:"severity" url:"severity"`
+	Description  string               `json:"description" url:"description"`
+	Evidence     *string              `json:"evidence,omitempty" url:"evidence,omitempty"`
+	Line         *int                 `json:"line,omitempty" url:"line,omitempty"`
+	Column       *int                 `json:"column,omitempty" url:"column,omitempty"`
+	Fingerprint  string               `json:"fingerprint" url:"fingerprint"`
+	Verification *FindingVerification `json:"verification,omitempty" url:"verification,omitempty"`
+	Consensus    *FindingConsensus    `json:"consensus,omitempty" url:"consensus,omitempty"`
+	Model        *string              `json:"model,omitempty" url:"model,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (f *Finding) GetExtraProperties() map[string]interface{} {
+	return f.extraProperties
+}
+
+func (f *Finding) UnmarshalJSON(data []byte) error {
+	type unmarshaler Finding
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*f = Finding(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *f)
+	if err != nil {
+		return err
+	}
+	f.extraProperties = extraProperties
+
+	f._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (f *Finding) String() string {
+	if len(f._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(f._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(f); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", f)
+}
+
+type FindingCategory string
+
+const (
+	FindingCategoryVulnerability   FindingCategory = "VULNERABILITY"
+	FindingCategorySensitiveData   FindingCategory = "SENSITIVE_DATA"
+	FindingCategoryPromptInjection FindingCategory = "PROMPT_INJECTION"
+)
+
+func NewFindingCategoryFromString(s string) (FindingCategory, error) {
+	switch s {
+	case "VULNERABILITY":
+		return FindingCategoryVulnerability, nil
+	case "SENSITIVE_DATA":
+		return FindingCategorySensitiveData, nil
+	case "PROMPT_INJECTION":
+		return FindingCategoryPromptInjection, nil
+	}
+	var t FindingCategory
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (f FindingCategory) Ptr() *FindingCategory {
+	return &f
+}
+
+type FindingConsensus struct {
+	Models       []string      `json:"models" url:"models"`
+	Agreement    int           `json:"agreement" url:"agreement"`
+	EnsembleSize int           `json:"ensembleSize" url:"ensembleSize"`
+	JudgeVerdict *JudgeVerdict `json:"judgeVerdict,omitempty" url:"judgeVerdict,omitempty"`
+	JudgeReason  *string       `json:"judgeReason,omitempty" url:"judgeReason,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (f *FindingConsensus) GetExtraProperties() map[string]interface{} {
+	return f.extraProperties
+}
+
+func (f *FindingConsensus) UnmarshalJSON(data []byte) error {
+	type unmarshaler FindingConsensus
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*f = FindingConsensus(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *f)
+	if err != nil {
+		return err
+	}
+	f.extraProperties = extraProperties
+
+	f._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (f *FindingConsensus) String() string {
+	if len(f._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(f._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(f); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", f)
+}
+
+type FindingVerification struct {
+	Status           VerificationStatus `json:"status" url:"status"`
+	Method           VerificationMethod `json:"method" url:"method"`
+	OriginalSeverity *Severity          `json:"originalSeverity,omitempty" url:"originalSeverity,omitempty"`
+	Detail           *string            `json:"detail,omitempty" url:"detail,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (f *FindingVerification) GetExtraProperties() map[string]interface{} {
+	return f.extraProperties
+}
+
+func (f *FindingVerification) UnmarshalJSON(data []byte) error {
+	type unmarshaler FindingVerification
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*f = FindingVerification(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *f)
+	if err != nil {
+		return err
+	}
+	f.extraProperties = extraProperties
+
+	f._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (f *FindingVerification) String() string {
+	if len(f._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(f._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(f); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", f)
+}
+
+type HeaderChange struct {
+	Name     string  `json:"name" url:"name"`
+	Previous *string `json:"previous,omitempty" url:"previous,omitempty"`
+	Current  *string `json:"current,omitempty" url:"current,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (h *HeaderChange) GetExtraProperties() map[string]interface{} {
+	return h.extraProperties
+}
+
+func (h *HeaderChange) UnmarshalJSON(data []byte) error {
+	type unmarshaler HeaderChange
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*h = HeaderChange(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *h)
+	if err != nil {
+		return err
+	}
+	h.extraProperties = extraProperties
+
+	h._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (h *HeaderChange) String() string {
+	if len(h._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(h._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(h); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", h)
+}
+
+type HiddenText struct {
+	Technique HiddenTextTechnique `json:"technique" url:"technique"`
+	Text      string              `json:"text" url:"text"`
+	Line      int                 `json:"line" url:"line"`
+	Column    int                 `json:"column" url:"column"`
+	Injection bool                `json:"injection" url:"injection"`
+	Reasons   []string            `json:"reasons" url:"reasons"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (h *HiddenText) GetExtraProperties() map[string]interface{} {
+	return h.extraProperties
+}
+
+func (h *HiddenText) UnmarshalJSON(data []byte) error {
+	type unmarshaler HiddenText
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*h = HiddenText(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *h)
+	if err != nil {
+		return err
+	}
+	h.extraProperties = extraProperties
+
+	h._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (h *HiddenText) String() string {
+	if len(h._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(h._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(h); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", h)
+}
+
+type HiddenTextTechnique string
+
+const (
+	HiddenTextTechniqueComment          HiddenTextTechnique = "COMMENT"
+	HiddenTextTechniqueHiddenAttribute  HiddenTextTechnique = "HIDDEN_ATTRIBUTE"
+	HiddenTextTechniqueAriaHidden       HiddenTextTechnique = "ARIA_HIDDEN"
+	HiddenTextTechniqueDisplayNone      HiddenTextTechnique = "DISPLAY_NONE"
+	HiddenTextTechniqueVisibilityHidden HiddenTextTechnique = "VISIBILITY_HIDDEN"
+	HiddenTextTechniqueZeroOpacity      HiddenTextTechnique = "ZERO_OPACITY"
+	HiddenTextTechniqueZeroFontSize     HiddenTextTechnique = "ZERO_FONT_SIZE"
+	HiddenTextTechniqueOffscreen        HiddenTextTechnique = "OFFSCREEN"
+	HiddenTextTechniqueSameColor        HiddenTextTechnique = "SAME_COLOR"
+)
+
+func NewHiddenTextTechniqueFromString(s string) (HiddenTextTechnique, error) {
+	switch s {
+	case "COMMENT":
+		return HiddenTextTechniqueComment, nil
+	case "HIDDEN_ATTRIBUTE":
+		return HiddenTextTechniqueHiddenAttribute, nil
+	case "ARIA_HIDDEN":
+		return HiddenTextTechniqueAriaHidden, nil
+	case "DISPLAY_NONE":
+		return HiddenTextTechniqueDisplayNone, nil
+	case "VISIBILITY_HIDDEN":
+		return HiddenTextTechniqueVisibilityHidden, nil
+	case "ZERO_OPACITY":
+		return HiddenTextTechniqueZeroOpacity, nil
+	case "ZERO_FONT_SIZE":
+		return HiddenTextTechniqueZeroFontSize, nil
+	case "OFFSCREEN":
+		return HiddenTextTechniqueOffscreen, nil
+	case "SAME_COLOR":
+		return HiddenTextTechniqueSameColor, nil
+	}
+	var t HiddenTextTechnique
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (h HiddenTextTechnique) Ptr() *HiddenTextTechnique {
+	return &h
+}
+
+type InjectionCheck struct {
+	Patterns             []string      `json:"patterns" url:"patterns"`
+	HiddenText           []*HiddenText `json:"hiddenText" url:"hiddenText"`
+	CanaryTriggered      bool          `json:"canaryTriggered" url:"canaryTriggered"`
+	SuppressionSuspected bool          `json:"suppressionSuspected" url:"suppressionSuspected"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (i *InjectionCheck) GetExtraProperties() map[string]interface{} {
+	return i.extraProperties
+}
+
+func (i *InjectionCheck) UnmarshalJSON(data []byte) error {
+	type unmarshaler InjectionCheck
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*i = InjectionCheck(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *i)
+	if err != nil {
+		return err
+	}
+	i.extraProperties = extraProperties
+
+	i._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (i *InjectionCheck) String() string {
+	if len(i._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(i._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(i); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", i)
+}
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "QUEUED"
+	JobStatusRunning   JobStatus = "RUNNING"
+	JobStatusCompleted JobStatus = "COMPLETED"
+	JobStatusFailed    JobStatus = "FAILED"
+	JobStatusCancelled JobStatus = "CANCELLED"
+)
+
+func NewJobStatusFromString(s string) (JobStatus, error) {
+	switch s {
+	case "QUEUED":
+		return JobStatusQueued, nil
+	case "RUNNING":
+		return JobStatusRunning, nil
+	case "COMPLETED":
+		return JobStatusCompleted, nil
+	case "FAILED":
+		return JobStatusFailed, nil
+	case "CANCELLED":
+		return JobStatusCancelled, nil
+	}
+	var t JobStatus
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (j JobStatus) Ptr() *JobStatus {
+	return &j
+}
+
+type JudgeVerdict string
+
+const (
+	JudgeVerdictSupported JudgeVerdict = "SUPPORTED"
+	JudgeVerdictRejected  JudgeVerdict = "REJECTED"
+)
+
+func NewJudgeVerdictFromString(s string) (JudgeVerdict, error) {
+	switch s {
+	case "SUPPORTED":
+		return JudgeVerdictSupported, nil
+	case "REJECTED":
+		return JudgeVerdictRejected, nil
+	}
+	var t JudgeVerdict
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (j JudgeVerdict) Ptr() *JudgeVerdict {
+	return &j
+}
+
+type PlannedPrompt struct {
+	Kind           PromptKind `json:"kind" url:"kind"`
+	ChunkIndex     int        `json:"chunkIndex" url:"chunkIndex"`
+	SplitDepth     int        `json:"splitDepth" url:"splitDepth"`
+	ContentBytes   int64      `json:"contentBytes" url:"contentBytes"`
+	PromptTokens   int        `json:"promptTokens" url:"promptTokens"`
+	ExceedsContext bool       `json:"exceedsContext" url:"exceedsContext"`
+	Prompt         string     `json:"prompt" url:"prompt"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (p *PlannedPrompt) GetExtraProperties() map[string]interface{} {
+	return p.extraProperties
+}
+
+func (p *PlannedPrompt) UnmarshalJSON(data []byte) error {
+	type unmarshaler PlannedPrompt
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*p = PlannedPrompt(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *p)
+	if err != nil {
+		return err
+	}
+	p.extraProperties = extraProperties
+
+	p._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (p *PlannedPrompt) String() string {
+	if len(p._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(p._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(p); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", p)
+}
+
+type PromptKind string
+
+const (
+	PromptKindAnalysis  PromptKind = "ANALYSIS"
+	PromptKindSynthesis PromptKind = "SYNTHESIS"
+)
+
+func NewPromptKindFromString(s string) (PromptKind, error) {
+	switch s {
+	case "ANALYSIS":
+		return PromptKindAnalysis, nil
+	case "SYNTHESIS":
+		return PromptKindSynthesis, nil
+	}
+	var t PromptKind
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (p PromptKind) Ptr() *PromptKind {
+	return &p
+}
+
+type ReportStatus string
+
+const (
+	ReportStatusCompleted ReportStatus = "COMPLETED"
+	ReportStatusCancelled ReportStatus = "CANCELLED"
+	ReportStatusTimedOut  ReportStatus = "TIMED_OUT"
+)
+
+func NewReportStatusFromString(s string) (ReportStatus, error) {
+	switch s {
+	case "COMPLETED":
+		return ReportStatusCompleted, nil
+	case "CANCELLED":
+		return ReportStatusCancelled, nil
+	case "TIMED_OUT":
+		return ReportStatusTimedOut, nil
+	}
+	var t ReportStatus
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (r ReportStatus) Ptr() *ReportStatus {
+	return &r
+}
+
+type ResourceChange struct {
+	Url                   string             `json:"url" url:"url"`
+	Kind                  ResourceKind       `json:"kind" url:"kind"`
+	Change                ResourceChangeType `json:"change" url:"change"`
+	PreviousContentSha256 *string            `json:"previousContentSha256,omitempty" url:"previousContentSha256,omitempty"`
+	CurrentContentSha256  *string            `json:"currentContentSha256,omitempty" url:"currentContentSha256,omitempty"`
+	NewFindings           []*Finding         `json:"newFindings" url:"newFindings"`
+	ResolvedFindings      []*Finding         `json:"resolvedFindings" url:"resolvedFindings"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (r *ResourceChange) GetExtraProperties() map[string]interface{} {
+	return r.extraProperties
+}
+
+func (r *ResourceChange) UnmarshalJSON(data []byte) error {
+	type unmarshaler ResourceChange
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*r = ResourceChange(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *r)
+	if err != nil {
+		return err
+	}
+	r.extraProperties = extraProperties
+
+	r._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (r *ResourceChange) String() string {
+	if len(r._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(r._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(r); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", r)
+}
+
+type ResourceChangeType string
+
+const (
+	ResourceChangeTypeAdded   ResourceChangeType = "ADDED"
+	ResourceChangeTypeChanged ResourceChangeType = "CHANGED"
+	ResourceChangeTypeRemoved ResourceChangeType = "REMOVED"
+)
+
+func NewResourceChangeTypeFromString(s string) (ResourceChangeType, error) {
+	switch s {
+	case "ADDED":
+		return ResourceChangeTypeAdded, nil
+	case "CHANGED":
+		return ResourceChangeTypeChanged, nil
+	case "REMOVED":
+		return ResourceChangeTypeRemoved, nil
+	}
+	var t ResourceChangeType
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (r ResourceChangeType) Ptr() *ResourceChangeType {
+	return &r
+}
+
+type ResourceKind string
+
+const (
+	ResourceKindPage   ResourceKind = "PAGE"
+	ResourceKindScript ResourceKind = "SCRIPT"
+)
+
+func NewResourceKindFromString(s string) (ResourceKind, error) {
+	switch s {
+	case "PAGE":
+		return ResourceKindPage, nil
+	case "SCRIPT":
+		return ResourceKindScript, nil
+	}
+	var t ResourceKind
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (r ResourceKind) Ptr() *ResourceKind {
+	return &r
+}
+
+type ScreenedChunk struct {
+	Index        int      `json:"index" url:"index"`
+	ContentBytes int64    `json:"contentBytes" url:"contentBytes"`
+	Escalated    bool     `json:"escalated" url:"escalated"`
+	Reasons      []string `json:"reasons" url:"reasons"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (s *ScreenedChunk) GetExtraProperties() map[string]interface{} {
+	return s.extraProperties
+}
+
+func (s *ScreenedChunk) UnmarshalJSON(data []byte) error {
+	type unmarshaler ScreenedChunk
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = ScreenedChunk(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *s)
+	if err != nil {
+		return err
+	}
+	s.extraProperties = extraProperties
+
+	s._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (s *ScreenedChunk) String() string {
+	if len(s._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(s._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(s); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", s)
+}
+
+type ServerHealth struct {
+	Status      string `json:"status" url:"status"`
+	Model       string `json:"model" url:"model"`
+	Concurrency int    `json:"concurrency" url:"concurrency"`
+	Queued      int    `json:"queued" url:"queued"`
+	Running     int    `json:"running" url:"running"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (s *ServerHealth) GetExtraProperties() map[string]interface{} {
+	return s.extraProperties
+}
+
+func (s *ServerHealth) UnmarshalJSON(data []byte) error {
+	type unmarshaler ServerHealth
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*s = ServerHealth(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *s)
+	if err != nil {
+		return err
+	}
+	s.extraProperties = extraProperties
+
+	s._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (s *ServerHealth) String() string {
+	if len(s._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(s._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(s); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", s)
+}
+
+type Severity string
+
+const (
+	SeverityCritical Severity = "CRITICAL"
+	SeverityHigh     Severity = "HIGH"
+	SeverityMedium   Severity = "MEDIUM"
+	SeverityLow      Severity = "LOW"
+	SeverityInfo     Severity = "INFO"
+)
+
+func NewSeverityFromString(s string) (Severity, error) {
+	switch s {
+	case "CRITICAL":
+		return SeverityCritical, nil
+	case "HIGH":
+		return SeverityHigh, nil
+	case "MEDIUM":
+		return SeverityMedium, nil
+	case "LOW":
+		return SeverityLow, nil
+	case "INFO":
+		return SeverityInfo, nil
+	}
+	var t Severity
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (s Severity) Ptr() *Severity {
+	return &s
+}
+
+type TargetDiff struct {
+	Target                string     `json:"target" url:"target"`
+	NewFindings           []*Finding `json:"newFindings" url:"newFindings"`
+	ResolvedFindings      []*Finding `json:"resolvedFindings" url:"resolvedFindings"`
+	UnchangedFindings     []*Finding `json:"unchangedFindings" url:"unchangedFindings"`
+	PreviousContentSha256 *string    `json:"previousContentSha256,omitempty" url:"previousContentSha256,omitempty"`
+	CurrentContentSha256  *string    `json:"currentContentSha256,omitempty" url:"currentContentSha256,omitempty"`
+	ContentChanged        bool       `json:"contentChanged" url:"contentChanged"`
+	InBaseline            bool       `json:"inBaseline" url:"inBaseline"`
+	Error                 *string    `json:"error,omitempty" url:"error,omitempty"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (t *TargetDiff) GetExtraProperties() map[string]interface{} {
+	return t.extraProperties
+}
+
+func (t *TargetDiff) UnmarshalJSON(data []byte) error {
+	type unmarshaler TargetDiff
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*t = TargetDiff(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *t)
+	if err != nil {
+		return err
+	}
+	t.extraProperties = extraProperties
+
+	t._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (t *TargetDiff) String() string {
+	if len(t._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(t._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(t); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", t)
+}
+
 type UrlAssessment struct {
-	CodeSummary              string `json:"codeSummary" url:"codeSummary"`
-	PotentialVulnerabilities bool   `json:"potentialVulnerabilities" url:"potentialVulnerabilities"`
-	VulnerabilitiesSummary   string `json:"vulnerabilitiesSummary" url:"vulnerabilitiesSummary"`
-	PotentialSensitiveData   bool   `json:"potentialSensitiveData" url:"potentialSensitiveData"`
-	SensitiveDataSummary     string `json:"sensitiveDataSummary" url:"sensitiveDataSummary"`
+	CodeSummary              string     `json:"codeSummary" url:"codeSummary"`
+	PotentialVulnerabilities bool       `json:"potentialVulnerabilities" url:"potentialVulnerabilities"`
+	VulnerabilitiesSummary   string     `json:"vulnerabilitiesSummary" url:"vulnerabilitiesSummary"`
+	PotentialSensitiveData   bool       `json:"potentialSensitiveData" url:"potentialSensitiveData"`
+	SensitiveDataSummary     string     `json:"sensitiveDataSummary" url:"sensitiveDataSummary"`
+	Findings                 []*Finding `json:"findings,omitempty" url:"findings,omitempty"`
+	DiscardedFindings        []*Finding `json:"discardedFindings,omitempty" url:"discardedFindings,omitempty"`
+	HeuristicOnly            *bool      `json:"heuristicOnly,omitempty" url:"heuristicOnly,omitempty"`
 
 	extraProperties map[string]interface{}
 	_rawJSON        json.RawMessage
@@ -54,9 +1428,18 @@ func (u *UrlAssessment) String() string {
 }
 
 type UrlReport struct {
-	Target string   `json:"target" url:"target"`
-	Output string   `json:"output" url:"output"`
-	Errors []string `json:"errors,omitempty" url:"errors,omitempty"`
+	Target     string           `json:"target" url:"target"`
+	Status     *ReportStatus    `json:"status,omitempty" url:"status,omitempty"`
+	Output     string           `json:"output" url:"output"`
+	Fetch      *FetchMetadata   `json:"fetch,omitempty" url:"fetch,omitempty"`
+	Assessment *UrlAssessment   `json:"assessment,omitempty" url:"assessment,omitempty"`
+	Diff       *TargetDiff      `json:"diff,omitempty" url:"diff,omitempty"`
+	Plan       *AssessmentPlan  `json:"plan,omitempty" url:"plan,omitempty"`
+	Metrics    *UsageMetrics    `json:"metrics,omitempty" url:"metrics,omitempty"`
+	Ensemble   *EnsembleSummary `json:"ensemble,omitempty" url:"ensemble,omitempty"`
+	Cascade    *CascadeSummary  `json:"cascade,omitempty" url:"cascade,omitempty"`
+	Injection  *InjectionCheck  `json:"injection,omitempty" url:"injection,omitempty"`
+	Errors     []string         `json:"errors,omitempty" url:"errors,omitempty"`
 
 	extraProperties map[string]interface{}
 	_rawJSON        json.RawMessage
@@ -95,3 +1478,174 @@ func (u *UrlReport) String() string {
 	}
 	return fmt.Sprintf("%#v", u)
 }
+
+type UsageMetrics struct {
+	Generations           int     `json:"generations" url:"generations"`
+	CacheHits             int     `json:"cacheHits" url:"cacheHits"`
+	CanaryLeaks           int     `json:"canaryLeaks" url:"canaryLeaks"`
+	PromptTokens          int     `json:"promptTokens" url:"promptTokens"`
+	OutputTokens          int     `json:"outputTokens" url:"outputTokens"`
+	MaxSplitDepth         int     `json:"maxSplitDepth" url:"maxSplitDepth"`
+	WallTimeMs            int64   `json:"wallTimeMs" url:"wallTimeMs"`
+	GenerationTimeMs      int64   `json:"generationTimeMs" url:"generationTimeMs"`
+	LoadTimeMs            int64   `json:"loadTimeMs" url:"loadTimeMs"`
+	PromptEvalTimeMs      int64   `json:"promptEvalTimeMs" url:"promptEvalTimeMs"`
+	EvalTimeMs            int64   `json:"evalTimeMs" url:"evalTimeMs"`
+	PromptTokensPerSecond float64 `json:"promptTokensPerSecond" url:"promptTokensPerSecond"`
+	OutputTokensPerSecond float64 `json:"outputTokensPerSecond" url:"outputTokensPerSecond"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (u *UsageMetrics) GetExtraProperties() map[string]interface{} {
+	return u.extraProperties
+}
+
+func (u *UsageMetrics) UnmarshalJSON(data []byte) error {
+	type unmarshaler UsageMetrics
+	var value unmarshaler
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	*u = UsageMetrics(value)
+
+	extraProperties, err := core.ExtractExtraProperties(data, *u)
+	if err != nil {
+		return err
+	}
+	u.extraProperties = extraProperties
+
+	u._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (u *UsageMetrics) String() string {
+	if len(u._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(u._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(u); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", u)
+}
+
+type VerificationMethod string
+
+const (
+	VerificationMethodEvidenceMatch VerificationMethod = "EVIDENCE_MATCH"
+	VerificationMethodModel         VerificationMethod = "MODEL"
+	VerificationMethodNone          VerificationMethod = "NONE"
+)
+
+func NewVerificationMethodFromString(s string) (VerificationMethod, error) {
+	switch s {
+	case "EVIDENCE_MATCH":
+		return VerificationMethodEvidenceMatch, nil
+	case "MODEL":
+		return VerificationMethodModel, nil
+	case "NONE":
+		return VerificationMethodNone, nil
+	}
+	var t VerificationMethod
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (v VerificationMethod) Ptr() *VerificationMethod {
+	return &v
+}
+
+type VerificationStatus string
+
+const (
+	VerificationStatusVerified   VerificationStatus = "VERIFIED"
+	VerificationStatusUnverified VerificationStatus = "UNVERIFIED"
+	VerificationStatusRefuted    VerificationStatus = "REFUTED"
+)
+
+func NewVerificationStatusFromString(s string) (VerificationStatus, error) {
+	switch s {
+	case "VERIFIED":
+		return VerificationStatusVerified, nil
+	case "UNVERIFIED":
+		return VerificationStatusUnverified, nil
+	case "REFUTED":
+		return VerificationStatusRefuted, nil
+	}
+	var t VerificationStatus
+	return "", fmt.Errorf("%s is not a valid %T", s, t)
+}
+
+func (v VerificationStatus) Ptr() *VerificationStatus {
+	return &v
+}
+
+type WatchEvent struct {
+	Target         string            `json:"target" url:"target"`
+	CheckedAt      time.Time         `json:"checkedAt" url:"checkedAt"`
+	Check          int               `json:"check" url:"check"`
+	Baseline       bool              `json:"baseline" url:"baseline"`
+	ContentSha256  *string           `json:"contentSha256,omitempty" url:"contentSha256,omitempty"`
+	Assessed       bool              `json:"assessed" url:"assessed"`
+	Resources      []*ResourceChange `json:"resources" url:"resources"`
+	ChangedHeaders []*HeaderChange   `json:"changedHeaders" url:"changedHeaders"`
+	Metrics        *UsageMetrics     `json:"metrics,omitempty" url:"metrics,omitempty"`
+	Errors         []string          `json:"errors" url:"errors"`
+
+	extraProperties map[string]interface{}
+	_rawJSON        json.RawMessage
+}
+
+func (w *WatchEvent) GetExtraProperties() map[string]interface{} {
+	return w.extraProperties
+}
+
+func (w *WatchEvent) UnmarshalJSON(data []byte) error {
+	type embed WatchEvent
+	var unmarshaler = struct {
+		embed
+		CheckedAt *core.DateTime `json:"checkedAt"`
+	}{
+		embed: embed(*w),
+	}
+	if err := json.Unmarshal(data, &unmarshaler); err != nil {
+		return err
+	}
+	*w = WatchEvent(unmarshaler.embed)
+	w.CheckedAt = unmarshaler.CheckedAt.Time()
+
+	extraProperties, err := core.ExtractExtraProperties(data, *w)
+	if err != nil {
+		return err
+	}
+	w.extraProperties = extraProperties
+
+	w._rawJSON = json.RawMessage(data)
+	return nil
+}
+
+func (w *WatchEvent) MarshalJSON() ([]byte, error) {
+	type embed WatchEvent
+	var marshaler = struct {
+		embed
+		CheckedAt *core.DateTime `json:"checkedAt"`
+	}{
+		embed:     embed(*w),
+		CheckedAt: core.NewDateTime(w.CheckedAt),
+	}
+	return json.Marshal(marshaler)
+}
+
+func (w *WatchEvent) String() string {
+	if len(w._rawJSON) > 0 {
+		if value, err := core.StringifyJSON(w._rawJSON); err == nil {
+			return value
+		}
+	}
+	if value, err := core.StringifyJSON(w); err == nil {
+		return value
+	}
+	return fmt.Sprintf("%#v", w)
+}
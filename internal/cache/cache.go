@@ -0,0 +1,232 @@
+// Package cache stores model responses on disk so that recurring assessments of content that has not changed do not
+// repeat the same generations.
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultDir is the directory the cache is stored in unless configured otherwise.
+const DefaultDir = "/opt/method/webassess/var/data/cache"
+
+const entryExtension = ".json"
+
+// pruneMarker is the file whose modification time records when the cache was last pruned.
+const pruneMarker = ".pruned"
+
+// headerSize is the number of bytes read from the start of an entry to find its creation time, which is encoded first.
+const headerSize = 64
+
+// Cache is a directory of cached model responses. Entries older than TTL are treated as missing, and Prune evicts the
+// least recently used entries once the cache grows beyond MaxBytes. A zero TTL or MaxBytes disables the respective
+// limit.
+type Cache struct {
+	Dir      string
+	TTL      time.Duration
+	MaxBytes int64
+}
+
+type entry struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Response  string    `json:"response"`
+}
+
+// PruneResult summarizes the entries removed by Prune and the entries that remain.
+type PruneResult struct {
+	Removed      int
+	RemovedBytes int64
+	Entries      int
+	Bytes        int64
+}
+
+// Open creates the cache directory if necessary and returns the cache stored in it.
+func Open(dir string, ttl time.Duration, maxBytes int64) (*Cache, error) {
+	if dir == "" {
+		dir = DefaultDir
+	}
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	return &Cache{Dir: dir, TTL: ttl, MaxBytes: maxBytes}, nil
+}
+
+// Key derives a cache key from the parts that determine a response.
+func Key(parts ...string) string {
+	hash := sha256.New()
+	for _, part := range parts {
+		hash.Write([]byte(part))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Get returns the cached response for the key. Expired and unreadable entries are reported as missing. Reading an
+// entry marks it as recently used.
+func (c *Cache) Get(key string) (string, bool) {
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", false
+	}
+	var e entry
+	if err := json.Unmarshal(data, &e); err != nil {
+		return "", false
+	}
+	if c.expired(e.CreatedAt, time.Now()) {
+		return "", false
+	}
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+	return e.Response, true
+}
+
+// Put stores the response for the key. The entry is written to a temporary file first so that concurrent readers
+// never observe a partially written entry.
+func (c *Cache) Put(key string, response string) error {
+	data, err := json.Marshal(entry{CreatedAt: time.Now().UTC(), Response: response})
+	if err != nil {
+		return err
+	}
+	path := c.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create cache directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write cache entry: %v", err)
+	}
+	return nil
+}
+
+// Prune removes expired entries, then removes the least recently used entries until the cache fits within MaxBytes.
+// With all set, every entry is removed.
+func (c *Cache) Prune(all bool) (PruneResult, error) {
+	type file struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	now := time.Now()
+	result := PruneResult{}
+	files := []file{}
+	remove := func(path string, size int64) error {
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove cache entry: %v", err)
+		}
+		result.Removed++
+		result.RemovedBytes += size
+		return nil
+	}
+
+	err := filepath.WalkDir(c.Dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), entryExtension) {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		// Reading the creation time of every entry is only needed when entries expire
+		if all || (c.TTL > 0 && c.expired(createdAt(path, info), now)) {
+			return remove(path, info.Size())
+		}
+		files = append(files, file{path: path, size: info.Size(), modTime: info.ModTime()})
+		return nil
+	})
+	if err != nil {
+		return result, fmt.Errorf("failed to prune cache: %v", err)
+	}
+
+	var total int64
+	for _, f := range files {
+		total += f.size
+	}
+	if c.MaxBytes > 0 && total > c.MaxBytes {
+		sort.Slice(files, func(i, j int) bool { return files[i].modTime.Before(files[j].modTime) })
+		for len(files) > 0 && total > c.MaxBytes {
+			if err := remove(files[0].path, files[0].size); err != nil {
+				return result, err
+			}
+			total -= files[0].size
+			files = files[1:]
+		}
+	}
+
+	result.Entries = len(files)
+	result.Bytes = total
+	_ = os.WriteFile(filepath.Join(c.Dir, pruneMarker), nil, 0o640)
+	return result, nil
+}
+
+// PruneIfDue prunes the cache unless it was already pruned within the interval, so that frequent runs do not walk the
+// whole cache every time. It reports whether the cache was pruned.
+func (c *Cache) PruneIfDue(interval time.Duration) (bool, error) {
+	if info, err := os.Stat(filepath.Join(c.Dir, pruneMarker)); err == nil && time.Since(info.ModTime()) < interval {
+		return false, nil
+	}
+	_, err := c.Prune(false)
+	return true, err
+}
+
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.Dir, key[:2], key+entryExtension)
+}
+
+func (c *Cache) expired(created time.Time, now time.Time) bool {
+	return c.TTL > 0 && now.Sub(created) > c.TTL
+}
+
+// createdAt reads the creation time of an entry from its header, falling back to its modification time if it cannot
+// be read. Only the start of the entry is read, since the response it holds may be large.
+func createdAt(path string, info fs.FileInfo) time.Time {
+	f, err := os.Open(path)
+	if err != nil {
+		return info.ModTime()
+	}
+	defer f.Close()
+	header := make([]byte, headerSize)
+	n, _ := f.Read(header)
+	header = header[:n]
+
+	prefix := []byte(`{"createdAt":`)
+	if !bytes.HasPrefix(header, prefix) {
+		return info.ModTime()
+	}
+	end := bytes.IndexByte(header[len(prefix)+1:], '"')
+	if end < 0 {
+		return info.ModTime()
+	}
+	var created time.Time
+	if err := json.Unmarshal(header[len(prefix):len(prefix)+end+2], &created); err != nil || created.IsZero() {
+		return info.ModTime()
+	}
+	return created
+}
@@ -10,4 +10,6 @@ type RootFlags struct {
 	Verbose     bool
 	OllamaURL   string
 	OllamaModel ollama.Model
+	FailOn      string
+	FailOnError bool
 }
@@ -0,0 +1,395 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/Method-Security/webassess/internal/cache"
+	ollama "github.com/Method-Security/webassess/internal/ollama"
+	"gopkg.in/yaml.v3"
+)
+
+// SystemConfigDir is the directory searched for a configuration file after the user's XDG configuration directory.
+const SystemConfigDir = "/opt/method/webassess/var/conf"
+
+// EnvPrefix is the prefix of every environment variable read by webassess.
+const EnvPrefix = "WEBASSESS_"
+
+// configFileNames lists the file names searched for in each configuration directory, in order.
+var configFileNames = []string{"config.yaml", "config.yml", "config.toml"}
+
+// Config holds every setting that can be provided through a configuration file or environment variables. Settings
+// are resolved in the following order, with later sources taking precedence: built-in defaults, the configuration
+// file, the selected profile, WEBASSESS_* environment variables and finally command line flags.
+type Config struct {
+	Profile    string                   `json:"profile,omitempty" yaml:"profile,omitempty" toml:"profile,omitempty"`
+	Ollama     OllamaSettings           `json:"ollama" yaml:"ollama" toml:"ollama"`
+	Generation ollama.GenerationOptions `json:"generation" yaml:"generation" toml:"generation"`
+	Fetch      FetchSettings            `json:"fetch" yaml:"fetch" toml:"fetch"`
+	Analysis   AnalysisSettings         `json:"analysis" yaml:"analysis" toml:"analysis"`
+	Prompts    PromptSettings           `json:"prompts" yaml:"prompts" toml:"prompts"`
+	Timeouts   TimeoutSettings          `json:"timeouts" yaml:"timeouts" toml:"timeouts"`
+	Cache      CacheSettings            `json:"cache" yaml:"cache" toml:"cache"`
+	Serve      ServeSettings            `json:"serve" yaml:"serve" toml:"serve"`
+	Watch      WatchSettings            `json:"watch" yaml:"watch" toml:"watch"`
+	Output     OutputSettings           `json:"output" yaml:"output" toml:"output"`
+	Log        LogSettings              `json:"log" yaml:"log" toml:"log"`
+
+	// Profiles holds the built-in profiles along with those defined in the configuration file.
+	Profiles map[string]Profile `json:"-" yaml:"-" toml:"-"`
+}
+
+// OllamaSettings configures the connection to Ollama and the model used for assessments. When no URL is given and
+// Ollama is not already running, the CLI spawns a server that logs to LogFile and must respond within StartTimeout.
+// Keep leaves that server running after the CLI exits. AllowedModels are the models that may be downloaded, and the
+// digests that installed models must match. Models imported from local files are registered in ImportedModelsFile and
+// added to AllowedModels when the configuration is loaded.
+type OllamaSettings struct {
+	URL                string           `json:"url" yaml:"url" toml:"url"`
+	Model              string           `json:"model" yaml:"model" toml:"model"`
+	AllowDownload      bool             `json:"allowDownload" yaml:"allowDownload" toml:"allowDownload"`
+	AllowedModels      ollama.Allowlist `json:"allowedModels" yaml:"allowedModels" toml:"allowedModels"`
+	ImportedModelsFile string           `json:"importedModelsFile" yaml:"importedModelsFile" toml:"importedModelsFile"`
+	Keep               bool             `json:"keep" yaml:"keep" toml:"keep"`
+	LogFile            string           `json:"logFile" yaml:"logFile" toml:"logFile"`
+	StartTimeout       Duration         `json:"startTimeout" yaml:"startTimeout" toml:"startTimeout"`
+}
+
+// FetchSettings configures how target content is retrieved.
+type FetchSettings struct {
+	Timeout      Duration          `json:"timeout" yaml:"timeout" toml:"timeout"`
+	UserAgent    string            `json:"userAgent" yaml:"userAgent" toml:"userAgent"`
+	MaxBodyBytes int64             `json:"maxBodyBytes" yaml:"maxBodyBytes" toml:"maxBodyBytes"`
+	Headers      map[string]string `json:"headers,omitempty" yaml:"headers,omitempty" toml:"headers,omitempty"`
+}
+
+// AnalysisSettings configures the optional stages of an assessment. Ensemble lists the models that analyze each target
+// in place of the assessment model, and JudgeModel the model that adjudicates the findings they disagree on.
+// CascadeModel is the larger model that analyzes the chunks flagged while screening them with the assessment model.
+type AnalysisSettings struct {
+	ModelVerification bool             `json:"modelVerification" yaml:"modelVerification" toml:"modelVerification"`
+	Chunking          ChunkingSettings `json:"chunking" yaml:"chunking" toml:"chunking"`
+	Ensemble          []string         `json:"ensemble,omitempty" yaml:"ensemble,omitempty" toml:"ensemble,omitempty"`
+	JudgeModel        string           `json:"judgeModel,omitempty" yaml:"judgeModel,omitempty" toml:"judgeModel,omitempty"`
+	CascadeModel      string           `json:"cascadeModel,omitempty" yaml:"cascadeModel,omitempty" toml:"cascadeModel,omitempty"`
+}
+
+// ChunkingSettings configures how content is split across generation requests. Size is the maximum chunk size in
+// bytes used by the fixed strategy.
+type ChunkingSettings struct {
+	Strategy string `json:"strategy" yaml:"strategy" toml:"strategy"`
+	Size     int    `json:"size" yaml:"size" toml:"size"`
+}
+
+// PromptSettings configures the Go text/template templates used to render the analysis and synthesis prompts, and the
+// system message sent with them. Each template and the system message can be given inline or as the path of a file,
+// but not both; the built-in one is used when neither is set. Hints are additional lines of context made available to
+// the templates, and Examples are few-shot examples shown to the model before every analysis prompt.
+type PromptSettings struct {
+	Analysis      string          `json:"analysis,omitempty" yaml:"analysis,omitempty" toml:"analysis,omitempty"`
+	AnalysisFile  string          `json:"analysisFile,omitempty" yaml:"analysisFile,omitempty" toml:"analysisFile,omitempty"`
+	Synthesis     string          `json:"synthesis,omitempty" yaml:"synthesis,omitempty" toml:"synthesis,omitempty"`
+	SynthesisFile string          `json:"synthesisFile,omitempty" yaml:"synthesisFile,omitempty" toml:"synthesisFile,omitempty"`
+	System        string          `json:"system,omitempty" yaml:"system,omitempty" toml:"system,omitempty"`
+	SystemFile    string          `json:"systemFile,omitempty" yaml:"systemFile,omitempty" toml:"systemFile,omitempty"`
+	Hints         []string        `json:"hints,omitempty" yaml:"hints,omitempty" toml:"hints,omitempty"`
+	Examples      []PromptExample `json:"examples,omitempty" yaml:"examples,omitempty" toml:"examples,omitempty"`
+}
+
+// PromptExample is a few-shot example of the analysis: a piece of content, rendered with the analysis template, and the
+// JSON answer the model is expected to give for it.
+type PromptExample struct {
+	Content string `json:"content" yaml:"content" toml:"content"`
+	Answer  string `json:"answer" yaml:"answer" toml:"answer"`
+}
+
+// Templates returns the analysis and synthesis template sources, reading them from their files where configured. An
+// empty string means the built-in template should be used.
+func (p PromptSettings) Templates() (string, string, error) {
+	analysis, err := resolveSource("analysis template", p.Analysis, p.AnalysisFile)
+	if err != nil {
+		return "", "", err
+	}
+	synthesis, err := resolveSource("synthesis template", p.Synthesis, p.SynthesisFile)
+	if err != nil {
+		return "", "", err
+	}
+	return analysis, synthesis, nil
+}
+
+// SystemPrompt returns the system message, reading it from its file where configured. An empty string means the
+// built-in system message should be used.
+func (p PromptSettings) SystemPrompt() (string, error) {
+	return resolveSource("system prompt", p.System, p.SystemFile)
+}
+
+func resolveSource(name string, inline string, path string) (string, error) {
+	if inline != "" && path != "" {
+		return "", fmt.Errorf("both an inline %s and a %s file are configured", name, name)
+	}
+	if path == "" {
+		return inline, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %v", name, err)
+	}
+	return string(data), nil
+}
+
+// TimeoutSettings bounds how long assessments may take, where zero disables a timeout. Target applies to the fetch and
+// analysis of each target, and Overall to the whole command, including starting Ollama and downloading models.
+type TimeoutSettings struct {
+	Target  Duration `json:"target" yaml:"target" toml:"target"`
+	Overall Duration `json:"overall" yaml:"overall" toml:"overall"`
+}
+
+// CacheSettings configures the on-disk cache of model responses. Entries older than TTL are ignored, and pruning
+// evicts the least recently used entries once the cache is larger than MaxBytes.
+type CacheSettings struct {
+	Enabled  bool     `json:"enabled" yaml:"enabled" toml:"enabled"`
+	Dir      string   `json:"dir" yaml:"dir" toml:"dir"`
+	TTL      Duration `json:"ttl" yaml:"ttl" toml:"ttl"`
+	MaxBytes int64    `json:"maxBytes" yaml:"maxBytes" toml:"maxBytes"`
+}
+
+// ServeSettings configures the HTTP server started by the serve command. KeepAlive is how long the model stays
+// loaded after the last request, where a negative value keeps it loaded for as long as Ollama runs.
+type ServeSettings struct {
+	Address         string   `json:"address" yaml:"address" toml:"address"`
+	Concurrency     int      `json:"concurrency" yaml:"concurrency" toml:"concurrency"`
+	QueueSize       int      `json:"queueSize" yaml:"queueSize" toml:"queueSize"`
+	JobRetention    Duration `json:"jobRetention" yaml:"jobRetention" toml:"jobRetention"`
+	ShutdownTimeout Duration `json:"shutdownTimeout" yaml:"shutdownTimeout" toml:"shutdownTimeout"`
+	KeepAlive       Duration `json:"keepAlive" yaml:"keepAlive" toml:"keepAlive"`
+}
+
+// WatchSettings configures the watch command. StateFile is where the state of the last check is kept, so that a
+// restarted watcher does not start a new baseline; it is only kept in memory when empty.
+type WatchSettings struct {
+	Interval  Duration `json:"interval" yaml:"interval" toml:"interval"`
+	StateFile string   `json:"stateFile" yaml:"stateFile" toml:"stateFile"`
+}
+
+// OutputSettings configures how and where the report is written, and the exit code policy.
+type OutputSettings struct {
+	Format      string `json:"format" yaml:"format" toml:"format"`
+	File        string `json:"file" yaml:"file" toml:"file"`
+	FailOn      string `json:"failOn" yaml:"failOn" toml:"failOn"`
+	FailOnError bool   `json:"failOnError" yaml:"failOnError" toml:"failOnError"`
+}
+
+// LogSettings configures the CLI logging.
+type LogSettings struct {
+	Quiet   bool `json:"quiet" yaml:"quiet" toml:"quiet"`
+	Verbose bool `json:"verbose" yaml:"verbose" toml:"verbose"`
+}
+
+// Duration is a time.Duration that is written as a Go duration string, such as "30s", in configuration files.
+type Duration time.Duration
+
+func (d Duration) MarshalText() ([]byte, error) {
+	return []byte(time.Duration(d).String()), nil
+}
+
+func (d *Duration) UnmarshalText(text []byte) error {
+	parsed, err := time.ParseDuration(string(text))
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// DefaultConfig returns the built-in defaults, which match the defaults of the corresponding command line flags.
+func DefaultConfig() Config {
+	return Config{
+		Ollama: OllamaSettings{
+			Model:              "qwen2.5:0.5b",
+			AllowedModels:      ollama.DefaultAllowlist(),
+			ImportedModelsFile: ollama.DefaultImportedModelsFile,
+			LogFile:            ollama.DefaultLogFile,
+			StartTimeout:       Duration(time.Minute),
+		},
+		Analysis: AnalysisSettings{
+			ModelVerification: true,
+			Chunking: ChunkingSettings{
+				Strategy: "recursive",
+				Size:     16000,
+			},
+		},
+		Cache: CacheSettings{
+			Enabled:  true,
+			Dir:      cache.DefaultDir,
+			TTL:      Duration(7 * 24 * time.Hour),
+			MaxBytes: 512 << 20,
+		},
+		Serve: ServeSettings{
+			Address:         "127.0.0.1:8080",
+			Concurrency:     1,
+			QueueSize:       100,
+			JobRetention:    Duration(time.Hour),
+			ShutdownTimeout: Duration(30 * time.Second),
+			KeepAlive:       Duration(-1),
+		},
+		Watch: WatchSettings{
+			Interval: Duration(time.Hour),
+		},
+		Output: OutputSettings{
+			Format: "signal",
+		},
+	}
+}
+
+// LoadConfig resolves the configuration from the built-in defaults, the configuration file, the selected profile and
+// the environment. If path is empty, the file is discovered with FindConfigFile; a missing file is not an error in
+// that case. The profile is selected by the profile argument, the WEBASSESS_PROFILE environment variable or the
+// profile key of the configuration file, in that order. The path of the file that was loaded is returned, or an empty
+// string if no file was found.
+func LoadConfig(path string, profile string) (Config, string, error) {
+	cfg := DefaultConfig()
+	cfg.Profiles = BuiltInProfiles()
+
+	if path == "" {
+		path = os.Getenv(EnvPrefix + "CONFIG")
+	}
+	if path == "" {
+		path = FindConfigFile()
+	}
+	if path != "" {
+		if err := readConfigFile(path, &cfg); err != nil {
+			return cfg, path, err
+		}
+	}
+
+	if profile == "" {
+		profile = os.Getenv(EnvPrefix + "PROFILE")
+	}
+	if profile == "" {
+		profile = cfg.Profile
+	}
+	if profile != "" {
+		if err := cfg.ApplyProfile(profile); err != nil {
+			return cfg, path, err
+		}
+	}
+
+	if err := applyEnvironment(&cfg); err != nil {
+		return cfg, path, err
+	}
+	if err := cfg.Ollama.AllowedModels.Validate(); err != nil {
+		return cfg, path, fmt.Errorf("invalid ollama.allowedModels: %v", err)
+	}
+	if cfg.Ollama.ImportedModelsFile != "" {
+		imported, err := ollama.LoadImportedModels(cfg.Ollama.ImportedModelsFile)
+		if err != nil {
+			return cfg, path, err
+		}
+		cfg.Ollama.AllowedModels = cfg.Ollama.AllowedModels.Merge(imported)
+	}
+	return cfg, path, nil
+}
+
+// RedactedValue replaces the values of secret settings in Redacted.
+const RedactedValue = "<redacted>"
+
+// Redacted returns a copy of the configuration whose fetch header values, where users put credentials such as
+// Authorization and Cookie headers, are replaced with RedactedValue.
+func (c Config) Redacted() Config {
+	if len(c.Fetch.Headers) == 0 {
+		return c
+	}
+	headers := make(map[string]string, len(c.Fetch.Headers))
+	for name := range c.Fetch.Headers {
+		headers[name] = RedactedValue
+	}
+	c.Fetch.Headers = headers
+	return c
+}
+
+// ConfigSearchPaths returns the candidate configuration files in the order they are searched.
+func ConfigSearchPaths() []string {
+	dirs := []string{}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		dirs = append(dirs, filepath.Join(xdg, "webassess"))
+	} else if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".config", "webassess"))
+	}
+	dirs = append(dirs, SystemConfigDir)
+
+	paths := []string{}
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	return paths
+}
+
+// FindConfigFile returns the first configuration file that exists in the search paths, or an empty string.
+func FindConfigFile() string {
+	for _, path := range ConfigSearchPaths() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+func readConfigFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".toml":
+		var file struct {
+			Profiles map[string]toml.Primitive `toml:"profiles"`
+		}
+		if _, err := toml.Decode(string(data), cfg); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+		metadata, err := toml.Decode(string(data), &file)
+		if err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+		for name, primitive := range file.Profiles {
+			primitive := primitive
+			var description struct {
+				Description string `toml:"description"`
+			}
+			if err := metadata.PrimitiveDecode(primitive, &description); err != nil {
+				return fmt.Errorf("failed to parse profile %s: %v", name, err)
+			}
+			cfg.Profiles[name] = Profile{
+				Name:        name,
+				Description: description.Description,
+				apply: func(c *Config) error {
+					return metadata.PrimitiveDecode(primitive, c)
+				},
+			}
+		}
+	case ".yaml", ".yml", ".json":
+		var file struct {
+			Profiles map[string]yaml.Node `yaml:"profiles"`
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+		for name, node := range file.Profiles {
+			cfg.Profiles[name] = newYAMLProfile(name, node, false)
+		}
+	default:
+		return errors.New("unsupported config file extension, expected .yaml, .yml, .json or .toml")
+	}
+	return nil
+}
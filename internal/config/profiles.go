@@ -0,0 +1,111 @@
+package config
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Profile is a named bundle of settings, such as the model, generation options, analysis stages, chunking strategy and
+// output format, that is applied on top of the configuration file when selected. A profile may contain any of the
+// keys of the configuration file; keys that are not present keep their configured value.
+type Profile struct {
+	Name        string
+	Description string
+	BuiltIn     bool
+	apply       func(*Config) error
+}
+
+// builtInProfiles holds the profiles shipped with the binary, written in the same form as the profiles section of a
+// YAML configuration file. Profiles of the same name in the configuration file replace these.
+const builtInProfiles = `
+fast:
+  description: Quick triage on the smallest model, without model verification of findings
+  ollama:
+    model: qwen2.5:0.5b
+  generation:
+    temperature: 0
+    numPredict: 1024
+  analysis:
+    modelVerification: false
+    chunking:
+      strategy: fixed
+      size: 8000
+deep:
+  description: Thorough analysis on a large model with a large context window and model verification of findings
+  ollama:
+    model: qwen2.5:14b
+  generation:
+    temperature: 0.1
+    numCtx: 32768
+  analysis:
+    modelVerification: true
+    chunking:
+      strategy: recursive
+ci:
+  description: Pipeline gating that writes SARIF and fails on high severity findings or assessment errors
+  ollama:
+    model: qwen2.5:7b
+  generation:
+    temperature: 0
+    seed: 42
+  analysis:
+    modelVerification: true
+  output:
+    format: sarif
+    failOn: high
+    failOnError: true
+`
+
+// BuiltInProfiles returns the profiles shipped with the binary.
+func BuiltInProfiles() map[string]Profile {
+	var nodes map[string]yaml.Node
+	if err := yaml.Unmarshal([]byte(builtInProfiles), &nodes); err != nil {
+		panic(fmt.Sprintf("invalid built-in profiles: %v", err))
+	}
+	profiles := map[string]Profile{}
+	for name, node := range nodes {
+		profiles[name] = newYAMLProfile(name, node, true)
+	}
+	return profiles
+}
+
+// ApplyProfile applies the named profile on top of the configuration and records it as the selected profile.
+func (c *Config) ApplyProfile(name string) error {
+	profile, ok := c.Profiles[name]
+	if !ok {
+		return fmt.Errorf("unknown profile %q. Available profiles are: %s", name, strings.Join(c.ProfileNames(), ", "))
+	}
+	if err := profile.apply(c); err != nil {
+		return fmt.Errorf("failed to apply profile %s: %v", name, err)
+	}
+	c.Profile = name
+	return nil
+}
+
+// ProfileNames returns the names of all available profiles in alphabetical order.
+func (c *Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func newYAMLProfile(name string, node yaml.Node, builtIn bool) Profile {
+	var description struct {
+		Description string `yaml:"description"`
+	}
+	_ = node.Decode(&description)
+	return Profile{
+		Name:        name,
+		Description: description.Description,
+		BuiltIn:     builtIn,
+		apply: func(c *Config) error {
+			return node.Decode(c)
+		},
+	}
+}
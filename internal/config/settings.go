@@ -0,0 +1,402 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	ollama "github.com/Method-Security/webassess/internal/ollama"
+	"github.com/spf13/pflag"
+)
+
+// setting binds a single configuration value to its environment variable and, where one exists, its command line flag.
+type setting struct {
+	env  string
+	flag string
+	get  func(*Config) string
+	set  func(*Config, string) error
+}
+
+// settings lists every configuration value that can be overridden through the environment or command line flags.
+var settings = []setting{
+	{
+		env:  "OLLAMA_URL",
+		flag: "ollama-url",
+		get:  func(c *Config) string { return c.Ollama.URL },
+		set:  func(c *Config, v string) error { c.Ollama.URL = v; return nil },
+	},
+	{
+		env:  "OLLAMA_MODEL",
+		flag: "ollama-model",
+		get:  func(c *Config) string { return c.Ollama.Model },
+		set:  func(c *Config, v string) error { c.Ollama.Model = v; return nil },
+	},
+	{
+		env:  "ALLOW_DOWNLOAD",
+		flag: "allow-download",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Ollama.AllowDownload) },
+		set:  func(c *Config, v string) error { return parseBool(v, &c.Ollama.AllowDownload) },
+	},
+	{
+		env: "ALLOWED_MODELS",
+		set: func(c *Config, v string) error {
+			allowlist, err := ollama.ParseAllowlist(v)
+			c.Ollama.AllowedModels = allowlist
+			return err
+		},
+	},
+	{
+		env: "IMPORTED_MODELS_FILE",
+		set: func(c *Config, v string) error { c.Ollama.ImportedModelsFile = v; return nil },
+	},
+	{
+		env:  "KEEP_OLLAMA",
+		flag: "keep-ollama",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Ollama.Keep) },
+		set:  func(c *Config, v string) error { return parseBool(v, &c.Ollama.Keep) },
+	},
+	{
+		env: "OLLAMA_LOG_FILE",
+		set: func(c *Config, v string) error { c.Ollama.LogFile = v; return nil },
+	},
+	{
+		env: "OLLAMA_START_TIMEOUT",
+		set: func(c *Config, v string) error { return parseDuration(v, &c.Ollama.StartTimeout) },
+	},
+	{
+		env: "TEMPERATURE",
+		set: func(c *Config, v string) error { return parseOptionalFloat(v, &c.Generation.Temperature) },
+	},
+	{
+		env: "TOP_P",
+		set: func(c *Config, v string) error { return parseOptionalFloat(v, &c.Generation.TopP) },
+	},
+	{
+		env: "TOP_K",
+		set: func(c *Config, v string) error { return parseOptionalInt(v, &c.Generation.TopK) },
+	},
+	{
+		env: "NUM_CTX",
+		set: func(c *Config, v string) error { return parseOptionalInt(v, &c.Generation.NumCtx) },
+	},
+	{
+		env: "NUM_PREDICT",
+		set: func(c *Config, v string) error { return parseOptionalInt(v, &c.Generation.NumPredict) },
+	},
+	{
+		env: "REPEAT_PENALTY",
+		set: func(c *Config, v string) error { return parseOptionalFloat(v, &c.Generation.RepeatPenalty) },
+	},
+	{
+		env: "SEED",
+		set: func(c *Config, v string) error { return parseOptionalInt(v, &c.Generation.Seed) },
+	},
+	{
+		env: "FETCH_TIMEOUT",
+		set: func(c *Config, v string) error {
+			timeout, err := time.ParseDuration(v)
+			c.Fetch.Timeout = Duration(timeout)
+			return err
+		},
+	},
+	{
+		env: "FETCH_USER_AGENT",
+		set: func(c *Config, v string) error { c.Fetch.UserAgent = v; return nil },
+	},
+	{
+		env: "FETCH_MAX_BODY_BYTES",
+		set: func(c *Config, v string) error {
+			size, err := strconv.ParseInt(v, 10, 64)
+			c.Fetch.MaxBodyBytes = size
+			return err
+		},
+	},
+	{
+		env:  "MODEL_VERIFICATION",
+		flag: "model-verification",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Analysis.ModelVerification) },
+		set:  func(c *Config, v string) error { return parseBool(v, &c.Analysis.ModelVerification) },
+	},
+	{
+		env: "CHUNKING_STRATEGY",
+		set: func(c *Config, v string) error { c.Analysis.Chunking.Strategy = v; return nil },
+	},
+	{
+		env: "CHUNK_SIZE",
+		set: func(c *Config, v string) error {
+			size, err := strconv.Atoi(v)
+			c.Analysis.Chunking.Size = size
+			return err
+		},
+	},
+	{
+		env:  "ENSEMBLE",
+		flag: "ensemble",
+		get:  func(c *Config) string { return strings.Join(c.Analysis.Ensemble, ",") },
+		set:  func(c *Config, v string) error { c.Analysis.Ensemble = parseList(v); return nil },
+	},
+	{
+		env:  "JUDGE_MODEL",
+		flag: "judge-model",
+		get:  func(c *Config) string { return c.Analysis.JudgeModel },
+		set:  func(c *Config, v string) error { c.Analysis.JudgeModel = v; return nil },
+	},
+	{
+		env:  "CASCADE_MODEL",
+		flag: "cascade-model",
+		get:  func(c *Config) string { return c.Analysis.CascadeModel },
+		set:  func(c *Config, v string) error { c.Analysis.CascadeModel = v; return nil },
+	},
+	{
+		env:  "ANALYSIS_TEMPLATE_FILE",
+		flag: "analysis-template",
+		get:  func(c *Config) string { return c.Prompts.AnalysisFile },
+		set:  func(c *Config, v string) error { c.Prompts.AnalysisFile = v; c.Prompts.Analysis = ""; return nil },
+	},
+	{
+		env:  "SYNTHESIS_TEMPLATE_FILE",
+		flag: "synthesis-template",
+		get:  func(c *Config) string { return c.Prompts.SynthesisFile },
+		set:  func(c *Config, v string) error { c.Prompts.SynthesisFile = v; c.Prompts.Synthesis = ""; return nil },
+	},
+	{
+		env:  "SYSTEM_PROMPT_FILE",
+		flag: "system-prompt",
+		get:  func(c *Config) string { return c.Prompts.SystemFile },
+		set:  func(c *Config, v string) error { c.Prompts.SystemFile = v; c.Prompts.System = ""; return nil },
+	},
+	{
+		env:  "NO_CACHE",
+		flag: "no-cache",
+		get:  func(c *Config) string { return strconv.FormatBool(!c.Cache.Enabled) },
+		set: func(c *Config, v string) error {
+			var disabled bool
+			err := parseBool(v, &disabled)
+			c.Cache.Enabled = !disabled
+			return err
+		},
+	},
+	{
+		env: "CACHE_DIR",
+		set: func(c *Config, v string) error { c.Cache.Dir = v; return nil },
+	},
+	{
+		env: "CACHE_TTL",
+		set: func(c *Config, v string) error {
+			ttl, err := time.ParseDuration(v)
+			c.Cache.TTL = Duration(ttl)
+			return err
+		},
+	},
+	{
+		env: "CACHE_MAX_BYTES",
+		set: func(c *Config, v string) error {
+			size, err := strconv.ParseInt(v, 10, 64)
+			c.Cache.MaxBytes = size
+			return err
+		},
+	},
+	{
+		env:  "TIMEOUT",
+		flag: "timeout",
+		get:  func(c *Config) string { return time.Duration(c.Timeouts.Target).String() },
+		set:  func(c *Config, v string) error { return parseDuration(v, &c.Timeouts.Target) },
+	},
+	{
+		env:  "OVERALL_TIMEOUT",
+		flag: "overall-timeout",
+		get:  func(c *Config) string { return time.Duration(c.Timeouts.Overall).String() },
+		set:  func(c *Config, v string) error { return parseDuration(v, &c.Timeouts.Overall) },
+	},
+	{
+		env:  "SERVE_ADDRESS",
+		flag: "address",
+		get:  func(c *Config) string { return c.Serve.Address },
+		set:  func(c *Config, v string) error { c.Serve.Address = v; return nil },
+	},
+	{
+		env:  "SERVE_CONCURRENCY",
+		flag: "concurrency",
+		get:  func(c *Config) string { return strconv.Itoa(c.Serve.Concurrency) },
+		set:  func(c *Config, v string) error { return parseInt(v, &c.Serve.Concurrency) },
+	},
+	{
+		env:  "SERVE_QUEUE_SIZE",
+		flag: "queue-size",
+		get:  func(c *Config) string { return strconv.Itoa(c.Serve.QueueSize) },
+		set:  func(c *Config, v string) error { return parseInt(v, &c.Serve.QueueSize) },
+	},
+	{
+		env:  "SERVE_JOB_RETENTION",
+		flag: "job-retention",
+		get:  func(c *Config) string { return time.Duration(c.Serve.JobRetention).String() },
+		set:  func(c *Config, v string) error { return parseDuration(v, &c.Serve.JobRetention) },
+	},
+	{
+		env:  "SERVE_SHUTDOWN_TIMEOUT",
+		flag: "shutdown-timeout",
+		get:  func(c *Config) string { return time.Duration(c.Serve.ShutdownTimeout).String() },
+		set:  func(c *Config, v string) error { return parseDuration(v, &c.Serve.ShutdownTimeout) },
+	},
+	{
+		env:  "SERVE_KEEP_ALIVE",
+		flag: "keep-alive",
+		get:  func(c *Config) string { return time.Duration(c.Serve.KeepAlive).String() },
+		set:  func(c *Config, v string) error { return parseDuration(v, &c.Serve.KeepAlive) },
+	},
+	{
+		env:  "WATCH_INTERVAL",
+		flag: "interval",
+		get:  func(c *Config) string { return time.Duration(c.Watch.Interval).String() },
+		set:  func(c *Config, v string) error { return parseDuration(v, &c.Watch.Interval) },
+	},
+	{
+		env:  "WATCH_STATE_FILE",
+		flag: "state-file",
+		get:  func(c *Config) string { return c.Watch.StateFile },
+		set:  func(c *Config, v string) error { c.Watch.StateFile = v; return nil },
+	},
+	{
+		env:  "OUTPUT",
+		flag: "output",
+		get:  func(c *Config) string { return c.Output.Format },
+		set:  func(c *Config, v string) error { c.Output.Format = v; return nil },
+	},
+	{
+		env:  "OUTPUT_FILE",
+		flag: "output-file",
+		get:  func(c *Config) string { return c.Output.File },
+		set:  func(c *Config, v string) error { c.Output.File = v; return nil },
+	},
+	{
+		env:  "FAIL_ON",
+		flag: "fail-on",
+		get:  func(c *Config) string { return c.Output.FailOn },
+		set:  func(c *Config, v string) error { c.Output.FailOn = v; return nil },
+	},
+	{
+		env:  "FAIL_ON_ERROR",
+		flag: "fail-on-error",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Output.FailOnError) },
+		set:  func(c *Config, v string) error { return parseBool(v, &c.Output.FailOnError) },
+	},
+	{
+		env:  "QUIET",
+		flag: "quiet",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Log.Quiet) },
+		set:  func(c *Config, v string) error { return parseBool(v, &c.Log.Quiet) },
+	},
+	{
+		env:  "VERBOSE",
+		flag: "verbose",
+		get:  func(c *Config) string { return strconv.FormatBool(c.Log.Verbose) },
+		set:  func(c *Config, v string) error { return parseBool(v, &c.Log.Verbose) },
+	},
+}
+
+// EnvironmentVariables returns the names of all environment variables read by webassess.
+func EnvironmentVariables() []string {
+	names := []string{EnvPrefix + "CONFIG", EnvPrefix + "PROFILE"}
+	for _, s := range settings {
+		names = append(names, EnvPrefix+s.env)
+	}
+	return names
+}
+
+func applyEnvironment(cfg *Config) error {
+	for _, s := range settings {
+		value, ok := os.LookupEnv(EnvPrefix + s.env)
+		if !ok || value == "" {
+			continue
+		}
+		if err := s.set(cfg, value); err != nil {
+			return fmt.Errorf("invalid value for %s%s: %v", EnvPrefix, s.env, err)
+		}
+	}
+	return nil
+}
+
+// ApplyFlags reconciles the configuration with the command line flags. Flags that were explicitly set take precedence
+// and are copied into the configuration, while flags that were not set are given the configured value so that
+// commands reading their flags observe the effective configuration.
+func ApplyFlags(cfg *Config, flags *pflag.FlagSet) error {
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		flag := flags.Lookup(s.flag)
+		if flag == nil {
+			continue
+		}
+		if flag.Changed {
+			if err := s.set(cfg, flag.Value.String()); err != nil {
+				return fmt.Errorf("invalid value for --%s: %v", s.flag, err)
+			}
+			continue
+		}
+		if err := flags.Set(s.flag, s.get(cfg)); err != nil {
+			return fmt.Errorf("invalid configured value for %s: %v", s.flag, err)
+		}
+		// Setting the flag marks it as changed, which would hide that the value came from the configuration
+		flag.Changed = false
+	}
+	return nil
+}
+
+func parseBool(value string, target *bool) error {
+	parsed, err := strconv.ParseBool(value)
+	if err != nil {
+		return err
+	}
+	*target = parsed
+	return nil
+}
+
+// parseList splits a comma-separated list, dropping empty entries.
+func parseList(value string) []string {
+	list := []string{}
+	for _, entry := range strings.Split(value, ",") {
+		if entry = strings.TrimSpace(entry); entry != "" {
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+func parseInt(value string, target *int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*target = parsed
+	return nil
+}
+
+func parseDuration(value string, target *Duration) error {
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	*target = Duration(parsed)
+	return nil
+}
+
+func parseOptionalFloat(value string, target **float64) error {
+	parsed, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return err
+	}
+	*target = &parsed
+	return nil
+}
+
+func parseOptionalInt(value string, target **int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return err
+	}
+	*target = &parsed
+	return nil
+}
@@ -0,0 +1,165 @@
+// Package diff compares webassess reports from different runs so that recurring assessments only surface what has
+// changed since a baseline.
+package diff
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/url"
+)
+
+// LoadReports reads a report previously written by webassess. The file may contain a signal in the json or signal
+// output formats, whose content is a URL report or list of URL reports, or the bare URL report(s) themselves.
+func LoadReports(path string) ([]webassess.UrlReport, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read report: %v", err)
+	}
+	return ParseReports(data)
+}
+
+// ParseReports parses the contents of a previously written report. See LoadReports for the accepted shapes.
+func ParseReports(data []byte) ([]webassess.UrlReport, error) {
+	var envelope struct {
+		Content json.RawMessage `json:"content"`
+	}
+	if err := json.Unmarshal(data, &envelope); err == nil && len(envelope.Content) > 0 {
+		data = envelope.Content
+
+		// The signal output format base64 encodes the JSON content
+		var encoded string
+		if err := json.Unmarshal(data, &encoded); err == nil {
+			decoded, err := base64.StdEncoding.DecodeString(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode signal content: %v", err)
+			}
+			data = decoded
+		}
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if strings.HasPrefix(trimmed, "[") {
+		var reports []webassess.UrlReport
+		if err := json.Unmarshal(data, &reports); err != nil {
+			return nil, fmt.Errorf("failed to parse reports: %v", err)
+		}
+		return reports, nil
+	}
+
+	var report webassess.UrlReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("failed to parse report: %v", err)
+	}
+	if report.Target == "" {
+		return nil, errors.New("file does not contain a webassess URL report")
+	}
+	return []webassess.UrlReport{report}, nil
+}
+
+// Compare diffs every current report against the baseline report for the same target. Targets that are only present
+// in the baseline were not assessed in the current run, so their findings are reported as unchanged along with an error
+// rather than as resolved.
+func Compare(baselinePath string, baseline []webassess.UrlReport, current []webassess.UrlReport) webassess.DiffReport {
+	result := webassess.DiffReport{
+		Baseline: baselinePath,
+		Targets:  []*webassess.TargetDiff{},
+	}
+	for _, report := range current {
+		result.Targets = append(result.Targets, CompareTarget(FindTarget(baseline, report.Target), report))
+	}
+	for i := range baseline {
+		if FindTarget(current, baseline[i].Target) != nil {
+			continue
+		}
+		targetDiff := CompareTarget(&baseline[i], webassess.UrlReport{Target: baseline[i].Target})
+		errorMessage := "the target is not present in the current report"
+		targetDiff.Error = &errorMessage
+		result.Targets = append(result.Targets, targetDiff)
+	}
+	return result
+}
+
+// FindTarget returns the report for the target from the list of reports, or nil if the target is not present.
+func FindTarget(reports []webassess.UrlReport, target string) *webassess.UrlReport {
+	for i := range reports {
+		if reports[i].Target == target {
+			return &reports[i]
+		}
+	}
+	return nil
+}
+
+// CompareTarget matches the findings of the current report against those of the previous report by fingerprint.
+// Findings only present in the current report are new, findings only present in the previous report are resolved and
+// findings present in both are unchanged. A nil previous report treats every current finding as new. A current report
+// without an assessment, such as one whose fetch or model call failed, or with a heuristic only assessment says nothing
+// about the previous findings, so they are reported as unchanged and the diff records an error.
+func CompareTarget(previous *webassess.UrlReport, current webassess.UrlReport) *webassess.TargetDiff {
+	result := &webassess.TargetDiff{
+		Target:     current.Target,
+		InBaseline: previous != nil,
+	}
+	if current.Fetch != nil {
+		hash := current.Fetch.ContentSha256
+		result.CurrentContentSha256 = &hash
+	}
+	if previous != nil && previous.Fetch != nil {
+		hash := previous.Fetch.ContentSha256
+		result.PreviousContentSha256 = &hash
+	}
+	if result.PreviousContentSha256 != nil && result.CurrentContentSha256 != nil {
+		result.ContentChanged = *result.PreviousContentSha256 != *result.CurrentContentSha256
+	}
+
+	previousFindings := []*webassess.Finding{}
+	if previous != nil && previous.Assessment != nil {
+		previousFindings = previous.Assessment.Findings
+	}
+	if !url.ModelAssessed(current.Assessment) {
+		errorMessage := "the current report has no assessment by the model, so the previous findings could not be compared"
+		result.Error = &errorMessage
+		result.NewFindings = []*webassess.Finding{}
+		if current.Assessment != nil {
+			result.NewFindings, _, _ = CompareFindings(previousFindings, current.Assessment.Findings)
+		}
+		result.ResolvedFindings, result.UnchangedFindings = []*webassess.Finding{}, append([]*webassess.Finding{}, previousFindings...)
+		return result
+	}
+
+	result.NewFindings, result.ResolvedFindings, result.UnchangedFindings = CompareFindings(previousFindings, current.Assessment.Findings)
+	return result
+}
+
+// CompareFindings matches the current findings against the previous findings by fingerprint, and returns the findings
+// only present in the current list, those only present in the previous list and those present in both.
+func CompareFindings(previous []*webassess.Finding, current []*webassess.Finding) (added []*webassess.Finding, resolved []*webassess.Finding, unchanged []*webassess.Finding) {
+	added, resolved, unchanged = []*webassess.Finding{}, []*webassess.Finding{}, []*webassess.Finding{}
+
+	seen := map[string]bool{}
+	for _, finding := range previous {
+		seen[finding.Fingerprint] = true
+	}
+	matched := map[string]bool{}
+	for _, finding := range current {
+		if seen[finding.Fingerprint] {
+			unchanged = append(unchanged, finding)
+			matched[finding.Fingerprint] = true
+		} else {
+			added = append(added, finding)
+		}
+	}
+	for _, finding := range previous {
+		if !matched[finding.Fingerprint] {
+			resolved = append(resolved, finding)
+			// Guard against the previous list containing the same fingerprint twice
+			matched[finding.Fingerprint] = true
+		}
+	}
+	return added, resolved, unchanged
+}
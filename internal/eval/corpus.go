@@ -0,0 +1,112 @@
+// Package eval measures the quality of assessments against a labeled corpus of fixtures, so that changes to the model
+// or the prompts can be compared before they are rolled out.
+package eval
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"gopkg.in/yaml.v3"
+)
+
+// Corpus is a set of fixtures with the findings expected for each of them, along with the variants to evaluate.
+type Corpus struct {
+	// Path is the path of the corpus manifest. Relative paths in the manifest are resolved against its directory.
+	Path     string    `yaml:"-"`
+	Cases    []Case    `yaml:"cases"`
+	Variants []Variant `yaml:"variants"`
+}
+
+// Case is a single fixture. Target is the URL the fixture is presented as to the prompt templates and defaults to the
+// fixture's file name.
+type Case struct {
+	Name     string            `yaml:"name"`
+	File     string            `yaml:"file"`
+	Target   string            `yaml:"target"`
+	Expected []ExpectedFinding `yaml:"expected"`
+}
+
+// ExpectedFinding describes a finding that should be reported for a fixture. A reported finding of the same category
+// matches if its evidence contains Evidence, or its title contains Title, ignoring case and differences in whitespace.
+type ExpectedFinding struct {
+	Category string `yaml:"category"`
+	Evidence string `yaml:"evidence"`
+	Title    string `yaml:"title"`
+}
+
+// Variant is a model and prompt combination to evaluate. Template paths are relative to the corpus manifest. A variant
+// without templates uses the configured templates, while a variant that only sets one of them uses the built-in
+// template for the other. Generation options replace the configured options when set.
+type Variant struct {
+	Name              string                    `yaml:"name"`
+	Model             string                    `yaml:"model"`
+	AnalysisTemplate  string                    `yaml:"analysisTemplate"`
+	SynthesisTemplate string                    `yaml:"synthesisTemplate"`
+	Generation        *ollama.GenerationOptions `yaml:"generation"`
+}
+
+// LoadCorpus reads a corpus manifest and validates its cases.
+func LoadCorpus(path string) (Corpus, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Corpus{}, fmt.Errorf("failed to read corpus: %v", err)
+	}
+
+	var corpus Corpus
+	if err := yaml.Unmarshal(data, &corpus); err != nil {
+		return Corpus{}, fmt.Errorf("failed to parse corpus %s: %v", path, err)
+	}
+	corpus.Path = path
+
+	if len(corpus.Cases) == 0 {
+		return Corpus{}, errors.New("the corpus does not contain any cases")
+	}
+	for i := range corpus.Cases {
+		c := &corpus.Cases[i]
+		if c.File == "" {
+			return Corpus{}, fmt.Errorf("case %d does not specify a file", i+1)
+		}
+		if c.Name == "" {
+			c.Name = c.File
+		}
+		if c.Target == "" {
+			c.Target = filepath.Base(c.File)
+		}
+		for _, expected := range c.Expected {
+			if _, err := parseCategory(expected.Category); err != nil {
+				return Corpus{}, fmt.Errorf("case %s: %v", c.Name, err)
+			}
+			if expected.Evidence == "" && expected.Title == "" {
+				return Corpus{}, fmt.Errorf("case %s: expected findings must specify evidence or a title", c.Name)
+			}
+		}
+	}
+	for i, variant := range corpus.Variants {
+		if variant.Name == "" {
+			corpus.Variants[i].Name = variant.Model
+		}
+	}
+	return corpus, nil
+}
+
+// Resolve returns the path relative to the directory of the corpus manifest, unless it is absolute.
+func (c Corpus) Resolve(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(c.Path), path)
+}
+
+func parseCategory(category string) (webassess.FindingCategory, error) {
+	normalized := strings.NewReplacer("-", "_", " ", "_").Replace(strings.ToUpper(strings.TrimSpace(category)))
+	parsed, err := webassess.NewFindingCategoryFromString(normalized)
+	if err != nil {
+		return "", fmt.Errorf("invalid finding category %q. Valid categories are: vulnerability, sensitive_data, prompt_injection", category)
+	}
+	return parsed, nil
+}
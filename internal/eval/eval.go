@@ -0,0 +1,196 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/url"
+	"github.com/ollama/ollama/api"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// categories lists the finding categories reported on for every variant, in order.
+var categories = []webassess.FindingCategory{
+	webassess.FindingCategoryVulnerability,
+	webassess.FindingCategorySensitiveData,
+	webassess.FindingCategoryPromptInjection,
+}
+
+// Run evaluates every variant against every case of the corpus. Each case is assessed with the same pipeline as the
+// url command, using the options as the base for every variant. Precision, recall and F1 are reported per finding
+// category and overall; they are 0 when undefined, for instance when nothing was expected or reported. If the context
+// is cancelled, the remaining cases are skipped and the metrics cover the cases that were evaluated.
+func Run(ctx context.Context, corpus Corpus, variants []Variant, ollamaURL string, options url.Options) webassess.EvalReport {
+	report := webassess.EvalReport{
+		Corpus:   corpus.Path,
+		Variants: []*webassess.EvalVariantResult{},
+	}
+	for _, variant := range variants {
+		if ctx.Err() != nil {
+			break
+		}
+		report.Variants = append(report.Variants, runVariant(ctx, corpus, variant, ollamaURL, options))
+	}
+	report.Status = url.ReportStatus(ctx)
+	return report
+}
+
+func runVariant(ctx context.Context, corpus Corpus, variant Variant, ollamaURL string, options url.Options) *webassess.EvalVariantResult {
+	log := svc1log.FromContext(ctx)
+
+	result := &webassess.EvalVariantResult{
+		Name:       variant.Name,
+		Model:      variant.Model,
+		Categories: []*webassess.EvalCategoryMetrics{},
+		Cases:      []*webassess.EvalCaseResult{},
+		Errors:     []string{},
+	}
+	if variant.AnalysisTemplate != "" {
+		result.AnalysisTemplate = &variant.AnalysisTemplate
+	}
+	if variant.SynthesisTemplate != "" {
+		result.SynthesisTemplate = &variant.SynthesisTemplate
+	}
+	result.Overall = newCategoryMetrics(nil, counts{})
+
+	if variant.AnalysisTemplate != "" || variant.SynthesisTemplate != "" {
+		prompts, err := loadTemplates(corpus, variant)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return result
+		}
+		options.Prompts = prompts
+	}
+	if variant.Generation != nil {
+		options.Generation = *variant.Generation
+	}
+
+	model, err := ollama.GetModel(ctx, ollamaURL, variant.Model)
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("failed to get ollama model %s: %v", variant.Model, err))
+		return result
+	}
+	client, err := api.ClientFromEnvironment()
+	if err != nil {
+		result.Errors = append(result.Errors, fmt.Sprintf("Failed to create Ollama client: %v", err))
+		return result
+	}
+
+	totals := map[webassess.FindingCategory]*counts{}
+	for _, category := range categories {
+		totals[category] = &counts{}
+	}
+	for i, c := range corpus.Cases {
+		log.Info("Evaluating case", svc1log.SafeParam("variant", variant.Name), svc1log.SafeParam("case", c.Name))
+		generator := ollama.NewGenerator(client, model, options.Generation)
+		caseResult := runCase(ctx, corpus, c, generator, options)
+		// A case interrupted by the cancellation of the run would count its expected findings as missed
+		if ctx.Err() != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("evaluation interrupted after %d of %d cases: %v", i, len(corpus.Cases), ctx.Err()))
+			break
+		}
+		result.Cases = append(result.Cases, caseResult.result)
+
+		result.TotalLatencyMs += caseResult.result.LatencyMs
+		result.PromptTokens += caseResult.result.PromptTokens
+		result.CompletionTokens += caseResult.result.CompletionTokens
+		for category, score := range caseResult.scores {
+			if totals[category] == nil {
+				totals[category] = &counts{}
+			}
+			totals[category].add(*score)
+		}
+	}
+	if len(result.Cases) > 0 {
+		result.MeanLatencyMs = float64(result.TotalLatencyMs) / float64(len(result.Cases))
+	}
+
+	overall := counts{}
+	for _, category := range categories {
+		category := category
+		result.Categories = append(result.Categories, newCategoryMetrics(&category, *totals[category]))
+		overall.add(*totals[category])
+	}
+	result.Overall = newCategoryMetrics(nil, overall)
+	return result
+}
+
+type caseOutcome struct {
+	result *webassess.EvalCaseResult
+	scores map[webassess.FindingCategory]*counts
+}
+
+func runCase(ctx context.Context, corpus Corpus, c Case, generator *ollama.Generator, options url.Options) caseOutcome {
+	result := &webassess.EvalCaseResult{
+		Name:     c.Name,
+		File:     c.File,
+		Findings: []*webassess.Finding{},
+	}
+
+	content, err := os.ReadFile(corpus.Resolve(c.File))
+	if err != nil {
+		errorMessage := fmt.Sprintf("failed to read fixture: %v", err)
+		result.Error = &errorMessage
+		return finishCase(result, c, nil)
+	}
+
+	caseCtx, cancel := url.WithTargetTimeout(ctx, options.TargetTimeout)
+	defer cancel()
+	start := time.Now()
+	_, assessment, err := url.AssessContent(caseCtx, generator, c.Target, string(content), options)
+	result.LatencyMs = time.Since(start).Milliseconds()
+
+	usage := generator.Usage()
+	result.PromptTokens = usage.PromptTokens
+	result.CompletionTokens = usage.CompletionTokens
+	if err != nil {
+		errorMessage := err.Error()
+		result.Error = &errorMessage
+		return finishCase(result, c, nil)
+	}
+	url.AddInjectionFindings(assessment, string(content))
+	result.Findings = assessment.Findings
+	return finishCase(result, c, assessment.Findings)
+}
+
+// finishCase scores the findings of a case. Cases that failed report no findings, so every expected finding counts as
+// a false negative.
+func finishCase(result *webassess.EvalCaseResult, c Case, findings []*webassess.Finding) caseOutcome {
+	scores := scoreCase(c.Expected, findings)
+	for _, score := range scores {
+		result.TruePositives += score.truePositives
+		result.FalsePositives += score.falsePositives
+		result.FalseNegatives += score.falseNegatives
+	}
+	return caseOutcome{result: result, scores: scores}
+}
+
+func loadTemplates(corpus Corpus, variant Variant) (url.PromptTemplates, error) {
+	read := func(path string) (string, error) {
+		if path == "" {
+			return "", nil
+		}
+		data, err := os.ReadFile(corpus.Resolve(path))
+		if err != nil {
+			return "", fmt.Errorf("failed to read template for variant %s: %v", variant.Name, err)
+		}
+		return string(data), nil
+	}
+	analysis, err := read(variant.AnalysisTemplate)
+	if err != nil {
+		return url.PromptTemplates{}, err
+	}
+	synthesis, err := read(variant.SynthesisTemplate)
+	if err != nil {
+		return url.PromptTemplates{}, err
+	}
+	prompts, err := url.NewPromptTemplates(analysis, synthesis)
+	if err != nil {
+		return url.PromptTemplates{}, fmt.Errorf("variant %s: %v", variant.Name, err)
+	}
+	return prompts, nil
+}
@@ -0,0 +1,101 @@
+package eval
+
+import (
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+)
+
+// minimumReverseMatch is the shortest reported evidence that may match an expected evidence snippet it is contained
+// in, so that trivially short quotes do not match everything.
+const minimumReverseMatch = 8
+
+// counts holds the confusion counts of a category.
+type counts struct {
+	truePositives  int
+	falsePositives int
+	falseNegatives int
+}
+
+func (c *counts) add(other counts) {
+	c.truePositives += other.truePositives
+	c.falsePositives += other.falsePositives
+	c.falseNegatives += other.falseNegatives
+}
+
+// scoreCase matches the reported findings against the expected findings one to one, and returns the counts per
+// category.
+func scoreCase(expected []ExpectedFinding, findings []*webassess.Finding) map[webassess.FindingCategory]*counts {
+	scores := map[webassess.FindingCategory]*counts{}
+	score := func(category webassess.FindingCategory) *counts {
+		if scores[category] == nil {
+			scores[category] = &counts{}
+		}
+		return scores[category]
+	}
+
+	matched := make([]bool, len(findings))
+	for _, want := range expected {
+		category, _ := parseCategory(want.Category)
+		found := false
+		for i, finding := range findings {
+			if matched[i] || finding.Category != category || !matches(want, finding) {
+				continue
+			}
+			matched[i] = true
+			found = true
+			break
+		}
+		if found {
+			score(category).truePositives++
+		} else {
+			score(category).falseNegatives++
+		}
+	}
+	for i, finding := range findings {
+		if !matched[i] {
+			score(finding.Category).falsePositives++
+		}
+	}
+	return scores
+}
+
+func matches(want ExpectedFinding, finding *webassess.Finding) bool {
+	if want.Evidence != "" && finding.Evidence != nil {
+		expected := normalize(want.Evidence)
+		reported := normalize(*finding.Evidence)
+		if strings.Contains(reported, expected) {
+			return true
+		}
+		if len(reported) >= minimumReverseMatch && strings.Contains(expected, reported) {
+			return true
+		}
+	}
+	if want.Title != "" && strings.Contains(normalize(finding.Title), normalize(want.Title)) {
+		return true
+	}
+	return false
+}
+
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.Fields(s), " "))
+}
+
+func newCategoryMetrics(category *webassess.FindingCategory, c counts) *webassess.EvalCategoryMetrics {
+	metrics := &webassess.EvalCategoryMetrics{
+		Category:       category,
+		TruePositives:  c.truePositives,
+		FalsePositives: c.falsePositives,
+		FalseNegatives: c.falseNegatives,
+	}
+	if predicted := c.truePositives + c.falsePositives; predicted > 0 {
+		metrics.Precision = float64(c.truePositives) / float64(predicted)
+	}
+	if relevant := c.truePositives + c.falseNegatives; relevant > 0 {
+		metrics.Recall = float64(c.truePositives) / float64(relevant)
+	}
+	if metrics.Precision+metrics.Recall > 0 {
+		metrics.F1 = 2 * metrics.Precision * metrics.Recall / (metrics.Precision + metrics.Recall)
+	}
+	return metrics
+}
@@ -0,0 +1,107 @@
+package ollama
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// AllowedModel is a model that webassess is allowed to download and use. When Digest is set, the model is pinned:
+// the installed model must have exactly that digest, so that a tag that is re-pointed to a different build is
+// rejected.
+type AllowedModel struct {
+	Name   string `json:"name" yaml:"name" toml:"name"`
+	Digest string `json:"digest,omitempty" yaml:"digest,omitempty" toml:"digest,omitempty"`
+}
+
+// Allowlist is the list of models that webassess is allowed to download and use.
+type Allowlist []AllowedModel
+
+// DefaultAllowlist returns the built-in allowlist, which does not pin any digests.
+func DefaultAllowlist() Allowlist {
+	allowlist := make(Allowlist, 0, len(AllowedOllamaModels))
+	for _, name := range AllowedOllamaModels {
+		allowlist = append(allowlist, AllowedModel{Name: name})
+	}
+	return allowlist
+}
+
+// ParseAllowlist parses a comma separated list of models, each optionally pinned to a digest with NAME@DIGEST.
+func ParseAllowlist(value string) (Allowlist, error) {
+	allowlist := Allowlist{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, digest, _ := strings.Cut(entry, "@")
+		allowlist = append(allowlist, AllowedModel{Name: strings.TrimSpace(name), Digest: strings.TrimSpace(digest)})
+	}
+	return allowlist, allowlist.Validate()
+}
+
+// String formats the allowlist in the format read by ParseAllowlist.
+func (l Allowlist) String() string {
+	entries := make([]string, 0, len(l))
+	for _, model := range l {
+		if model.Digest != "" {
+			entries = append(entries, model.Name+"@"+model.Digest)
+		} else {
+			entries = append(entries, model.Name)
+		}
+	}
+	return strings.Join(entries, ",")
+}
+
+// Validate checks that every entry has a name and that pinned digests are complete SHA-256 digests. Abbreviated
+// digests, such as those printed by `ollama list`, are rejected since they would weaken the pin.
+func (l Allowlist) Validate() error {
+	for _, model := range l {
+		if model.Name == "" {
+			return fmt.Errorf("allowed model without a name")
+		}
+		if model.Digest == "" {
+			continue
+		}
+		digest := normalizeDigest(model.Digest)
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != 32 {
+			return fmt.Errorf("invalid digest %q for allowed model '%s'. Digests must be complete SHA-256 digests, as printed by `webassess models info`", model.Digest, model.Name)
+		}
+	}
+	return nil
+}
+
+// Find returns the allowlist entry for the model.
+func (l Allowlist) Find(name string) (AllowedModel, bool) {
+	for _, model := range l {
+		if model.Name == name {
+			return model, true
+		}
+	}
+	return AllowedModel{}, false
+}
+
+// IsAllowed returns true if the model is in the allowlist.
+func (l Allowlist) IsAllowed(name string) bool {
+	_, ok := l.Find(name)
+	return ok
+}
+
+// VerifyDigest checks the installed model against the digest it is pinned to. Models that are not in the allowlist or
+// are not pinned pass.
+func (l Allowlist) VerifyDigest(model Model) error {
+	allowed, ok := l.Find(model.Name)
+	if !ok || allowed.Digest == "" {
+		return nil
+	}
+	if normalizeDigest(allowed.Digest) != normalizeDigest(model.Digest) {
+		return fmt.Errorf("ollama model '%s' does not match its pinned digest: expected %s, installed %s. The tag may have been re-pointed to a different build",
+			model.Name, normalizeDigest(allowed.Digest), normalizeDigest(model.Digest))
+	}
+	return nil
+}
+
+// normalizeDigest strips the algorithm prefix from a digest, which Ollama omits when listing models.
+func normalizeDigest(digest string) string {
+	return strings.ToLower(strings.TrimPrefix(strings.TrimSpace(digest), "sha256:"))
+}
@@ -0,0 +1,301 @@
+package ollama
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Method-Security/webassess/internal/progress"
+	"github.com/ollama/ollama/api"
+)
+
+// DefaultImportedModelsFile is where the models imported with `webassess models import` are registered by default.
+const DefaultImportedModelsFile = "/opt/method/webassess/var/data/imported-models.json"
+
+// DefaultSystemPrompt is the system prompt applied to imported models, so that they behave like the models the
+// assessment prompts were tuned for.
+const DefaultSystemPrompt = `You are a web application security analyst. You review the HTML, JavaScript and other content of web pages for vulnerabilities and exposed sensitive data. You only report issues that are supported by the content you are given, and you always answer in the exact JSON format requested.`
+
+// ggufMagic is the first four bytes of a GGUF model file.
+var ggufMagic = []byte("GGUF")
+
+// ImportOptions controls how a model is created from a local file. System is the system prompt and Parameters are the
+// default generation parameters of the model; both replace those of a Modelfile. Quantize quantizes the model to the
+// given type, such as q4_K_M, if set.
+type ImportOptions struct {
+	System     string
+	Parameters GenerationOptions
+	Quantize   string
+}
+
+// modelfileInstruction is a single instruction of a Modelfile, such as FROM or PARAMETER, with its raw arguments.
+type modelfileInstruction struct {
+	command string
+	args    string
+}
+
+// ImportModel creates a model named name from a local GGUF file or Modelfile, without any access to the Ollama library.
+// Local files referenced by the FROM and ADAPTER instructions of a Modelfile, relative to the Modelfile, are uploaded
+// to Ollama first. Progress is reported to the reporter if it is not nil.
+func ImportModel(ctx context.Context, client *api.Client, name string, path string, options ImportOptions, reporter progress.Reporter) error {
+	task := progress.Start(reporter, "import "+name, progress.UnitBytes)
+	err := importModel(ctx, client, task, name, path, options)
+	task.Done(err)
+	return err
+}
+
+func importModel(ctx context.Context, client *api.Client, task *progress.Task, name string, path string, options ImportOptions) error {
+	isGGUF, err := isGGUFFile(path)
+	if err != nil {
+		return err
+	}
+	var instructions []modelfileInstruction
+	if isGGUF {
+		instructions = []modelfileInstruction{{command: "from", args: path}}
+	} else {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read Modelfile: %v", err)
+		}
+		instructions, err = parseModelfile(string(data))
+		if err != nil {
+			return fmt.Errorf("invalid Modelfile %s: %v", path, err)
+		}
+	}
+
+	dir := filepath.Dir(path)
+	hasFrom := false
+	for i, instruction := range instructions {
+		if instruction.command != "from" && instruction.command != "adapter" {
+			continue
+		}
+		hasFrom = hasFrom || instruction.command == "from"
+		file := unquote(instruction.args)
+		if !isGGUF && !filepath.IsAbs(file) {
+			file = filepath.Join(dir, file)
+		}
+		// Arguments that are not local files name a model that is already installed
+		if info, err := os.Stat(file); err != nil || info.IsDir() {
+			continue
+		}
+		task.Update(fmt.Sprintf("uploading %s", filepath.Base(file)), 0, 0)
+		digest, err := uploadBlob(ctx, client, file)
+		if err != nil {
+			return err
+		}
+		instructions[i].args = "@" + digest
+	}
+	if !hasFrom {
+		return fmt.Errorf("Modelfile %s has no FROM instruction", path)
+	}
+
+	modelfile := formatModelfile(applyImportOptions(instructions, options))
+	stream := true
+	err = client.Create(ctx, &api.CreateRequest{Model: name, Modelfile: modelfile, Stream: &stream, Quantize: options.Quantize}, func(resp api.ProgressResponse) error {
+		task.Update(resp.Status, resp.Completed, resp.Total)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create model '%s': %v", name, err)
+	}
+	return nil
+}
+
+// LoadImportedModels reads the models registered by ImportModel. A missing file is an empty registry.
+func LoadImportedModels(path string) (Allowlist, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Allowlist{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read imported models: %v", err)
+	}
+	var models Allowlist
+	if err := json.Unmarshal(data, &models); err != nil {
+		return nil, fmt.Errorf("failed to parse imported models %s: %v", path, err)
+	}
+	return models, models.Validate()
+}
+
+// RegisterImportedModel records an imported model, pinned to its digest, replacing any previous import under the same
+// name.
+func RegisterImportedModel(path string, model AllowedModel) error {
+	models, err := LoadImportedModels(path)
+	if err != nil {
+		return err
+	}
+	registered := Allowlist{model}
+	for _, existing := range models {
+		if existing.Name != model.Name {
+			registered = append(registered, existing)
+		}
+	}
+	sort.Slice(registered, func(i, j int) bool { return registered[i].Name < registered[j].Name })
+
+	data, err := json.MarshalIndent(registered, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("failed to create imported models directory: %v", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o640); err != nil {
+		return fmt.Errorf("failed to write imported models: %v", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write imported models: %v", err)
+	}
+	return nil
+}
+
+// Merge returns the allowlist with the models of other that it does not already contain.
+func (l Allowlist) Merge(other Allowlist) Allowlist {
+	merged := append(Allowlist{}, l...)
+	for _, model := range other {
+		if !merged.IsAllowed(model.Name) {
+			merged = append(merged, model)
+		}
+	}
+	return merged
+}
+
+// QualifiedModelName adds the latest tag to a model name without a tag, matching the names listed by Ollama.
+func QualifiedModelName(name string) string {
+	if strings.Contains(name, ":") {
+		return name
+	}
+	return name + ":latest"
+}
+
+func isGGUFFile(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open model file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+	magic := make([]byte, len(ggufMagic))
+	if _, err := io.ReadFull(file, magic); err != nil {
+		return false, nil
+	}
+	return bytes.Equal(magic, ggufMagic), nil
+}
+
+// uploadBlob uploads a file to Ollama and returns its digest, which a Modelfile references as @digest.
+func uploadBlob(ctx context.Context, client *api.Client, path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to open model file: %v", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to hash model file: %v", err)
+	}
+	digest := "sha256:" + hex.EncodeToString(hash.Sum(nil))
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("failed to read model file: %v", err)
+	}
+	if err := client.CreateBlob(ctx, digest, file); err != nil {
+		return "", fmt.Errorf("failed to upload %s: %v", path, err)
+	}
+	return digest, nil
+}
+
+// applyImportOptions replaces the system prompt and the parameters set by the options.
+func applyImportOptions(instructions []modelfileInstruction, options ImportOptions) []modelfileInstruction {
+	parameters := options.Parameters.Map()
+	result := make([]modelfileInstruction, 0, len(instructions)+len(parameters)+1)
+	for _, instruction := range instructions {
+		if instruction.command == "system" && options.System != "" {
+			continue
+		}
+		if instruction.command == "parameter" {
+			key, _, _ := strings.Cut(instruction.args, " ")
+			if _, ok := parameters[strings.ToLower(key)]; ok {
+				continue
+			}
+		}
+		result = append(result, instruction)
+	}
+	if options.System != "" {
+		result = append(result, modelfileInstruction{command: "system", args: `"""` + options.System + `"""`})
+	}
+	keys := make([]string, 0, len(parameters))
+	for key := range parameters {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		result = append(result, modelfileInstruction{command: "parameter", args: fmt.Sprintf("%s %v", key, parameters[key])})
+	}
+	return result
+}
+
+// parseModelfile splits a Modelfile into its instructions. Arguments wrapped in triple quotes may span several lines,
+// and comments and blank lines are dropped.
+func parseModelfile(modelfile string) ([]modelfileInstruction, error) {
+	instructions := []modelfileInstruction{}
+	scanner := bufio.NewScanner(strings.NewReader(modelfile))
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		command, args, _ := strings.Cut(line, " ")
+		args = strings.TrimSpace(args)
+		if strings.HasPrefix(args, `"""`) && (len(args) < 6 || !strings.HasSuffix(args, `"""`)) {
+			var builder strings.Builder
+			builder.WriteString(args)
+			closed := false
+			for scanner.Scan() {
+				builder.WriteString("\n")
+				builder.WriteString(scanner.Text())
+				if strings.HasSuffix(strings.TrimSpace(scanner.Text()), `"""`) {
+					closed = true
+					break
+				}
+			}
+			if !closed {
+				return nil, fmt.Errorf("unterminated \"\"\" in %s instruction", strings.ToUpper(command))
+			}
+			args = builder.String()
+		}
+		instructions = append(instructions, modelfileInstruction{command: strings.ToLower(command), args: args})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return instructions, nil
+}
+
+func formatModelfile(instructions []modelfileInstruction) string {
+	var builder strings.Builder
+	for _, instruction := range instructions {
+		builder.WriteString(strings.ToUpper(instruction.command))
+		builder.WriteString(" ")
+		builder.WriteString(instruction.args)
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func unquote(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
@@ -0,0 +1,130 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Method-Security/webassess/internal/progress"
+	"github.com/ollama/ollama/api"
+)
+
+// ModelDetails describes an installed model as reported by Ollama. Parameters are the default generation parameters
+// set by the model's Modelfile, and System is its system prompt.
+type ModelDetails struct {
+	Model
+	Family            string
+	ParameterSize     string
+	QuantizationLevel string
+	Format            string
+	Parameters        map[string]string
+	System            string
+}
+
+// ShowModel returns the details of an installed model, including its context window.
+func ShowModel(ctx context.Context, client *api.Client, name string) (ModelDetails, error) {
+	list, err := client.List(ctx)
+	if err != nil {
+		return ModelDetails{}, fmt.Errorf("failed to list models: %v", err)
+	}
+	var installed *api.ListModelResponse
+	for i := range list.Models {
+		if list.Models[i].Name == name {
+			installed = &list.Models[i]
+			break
+		}
+	}
+	if installed == nil {
+		return ModelDetails{}, fmt.Errorf("model '%s' is not installed", name)
+	}
+
+	show, err := client.Show(ctx, &api.ShowRequest{Model: name})
+	if err != nil {
+		return ModelDetails{}, fmt.Errorf("failed to show model '%s': %v", name, err)
+	}
+
+	return ModelDetails{
+		Model: Model{
+			Name:              installed.Name,
+			ModifiedAt:        installed.ModifiedAt.Format(time.RFC3339),
+			Size:              installed.Size,
+			Digest:            installed.Digest,
+			ContextWindowSize: contextLength(show.ModelInfo),
+		},
+		Family:            show.Details.Family,
+		ParameterSize:     show.Details.ParameterSize,
+		QuantizationLevel: show.Details.QuantizationLevel,
+		Format:            show.Details.Format,
+		Parameters:        parseParameters(show.Parameters),
+		System:            show.System,
+	}, nil
+}
+
+// PullModel downloads a model from the Ollama library, reporting its progress to the reporter if it is not nil.
+func PullModel(ctx context.Context, client *api.Client, name string, reporter progress.Reporter) error {
+	task := progress.Start(reporter, "pull "+name, progress.UnitBytes)
+	err := client.Pull(ctx, &api.PullRequest{Model: name}, func(resp api.ProgressResponse) error {
+		task.Update(resp.Status, resp.Completed, resp.Total)
+		return nil
+	})
+	if err != nil {
+		err = fmt.Errorf("failed to pull model '%s': %v", name, err)
+	}
+	task.Done(err)
+	return err
+}
+
+// RemoveModel deletes an installed model.
+func RemoveModel(ctx context.Context, client *api.Client, name string) error {
+	if err := client.Delete(ctx, &api.DeleteRequest{Model: name}); err != nil {
+		return fmt.Errorf("failed to remove model '%s': %v", name, err)
+	}
+	return nil
+}
+
+// contextLength returns the context length the model was trained with, which Ollama reports under an architecture
+// specific key such as qwen2.context_length, or 0 if it is not reported.
+func contextLength(modelInfo map[string]any) int {
+	keys := make([]string, 0, len(modelInfo))
+	for key := range modelInfo {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		if !strings.HasSuffix(key, ".context_length") {
+			continue
+		}
+		switch value := modelInfo[key].(type) {
+		case float64:
+			return int(value)
+		case int:
+			return value
+		case string:
+			if parsed, err := strconv.Atoi(value); err == nil {
+				return parsed
+			}
+		}
+	}
+	return 0
+}
+
+// parseParameters parses the parameters of a Modelfile, as returned by the show API, into a map. Parameters that are
+// repeated, such as stop, are joined with a comma.
+func parseParameters(parameters string) map[string]string {
+	result := map[string]string{}
+	for _, line := range strings.Split(parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		value := strings.Trim(strings.Join(fields[1:], " "), `"`)
+		if existing, ok := result[fields[0]]; ok {
+			value = existing + ", " + value
+		}
+		result[fields[0]] = value
+	}
+	return result
+}
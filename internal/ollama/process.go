@@ -0,0 +1,177 @@
+package ollama
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// DefaultLogFile is where the log of an Ollama server spawned by the CLI is written by default.
+const DefaultLogFile = "/opt/method/webassess/var/log/ollama.log"
+
+const (
+	runningCheckTimeout = 2 * time.Second
+	minReadyBackoff     = 100 * time.Millisecond
+	maxReadyBackoff     = 2 * time.Second
+)
+
+// ProcessOptions controls how an Ollama server is spawned. LogFile is appended to with the output of the server; it
+// is discarded if empty. ReadyTimeout is how long to wait for the server to respond before giving up.
+type ProcessOptions struct {
+	LogFile      string
+	ReadyTimeout time.Duration
+}
+
+// Process is an Ollama server spawned by the CLI. It runs in its own process group, so that interrupting the CLI from
+// a terminal does not also interrupt the server, and the CLI decides whether to stop it.
+type Process struct {
+	URL     string
+	LogFile string
+	cmd     *exec.Cmd
+	log     io.WriteCloser
+	exited  chan struct{}
+	err     error
+}
+
+// StartOllama spawns `ollama serve` on the standard URL and waits for it to respond, polling with an exponential
+// backoff until options.ReadyTimeout. If the server exits because another instance started listening on the standard
+// URL in the meantime, that instance is reused and a nil process is returned, since the CLI must not stop a server it
+// did not spawn.
+func StartOllama(ctx context.Context, options ProcessOptions) (*Process, error) {
+	log := svc1log.FromContext(ctx)
+
+	p := &Process{
+		URL:    OllamaStandardBaseURL,
+		exited: make(chan struct{}),
+	}
+	if options.LogFile != "" {
+		logFile, err := openLogFile(options.LogFile)
+		if err != nil {
+			// The log is only needed to troubleshoot the server, so it should not prevent assessments
+			log.Warn("Failed to open ollama log file, discarding its output", svc1log.SafeParam("logFile", options.LogFile), svc1log.Stacktrace(err))
+		} else {
+			p.LogFile = options.LogFile
+			p.log = logFile
+			_, _ = fmt.Fprintf(logFile, "--- ollama serve started by webassess at %s ---\n", time.Now().UTC().Format(time.RFC3339))
+		}
+	}
+
+	p.cmd = exec.Command("ollama", "serve")
+	p.cmd.Env = append(os.Environ(), "OLLAMA_HOST="+strings.TrimPrefix(OllamaStandardBaseURL, "http://"))
+	if p.log != nil {
+		p.cmd.Stdout = p.log
+		p.cmd.Stderr = p.log
+	}
+	setProcessGroup(p.cmd)
+	if err := p.cmd.Start(); err != nil {
+		p.closeLog()
+		return nil, fmt.Errorf("failed to start Ollama: %v", err)
+	}
+	go func() {
+		p.err = p.cmd.Wait()
+		close(p.exited)
+	}()
+	log.Info("Started ollama", svc1log.SafeParam("pid", p.cmd.Process.Pid), svc1log.SafeParam("logFile", p.LogFile))
+
+	if err := p.waitReady(ctx, options.ReadyTimeout); err != nil {
+		_ = p.Stop(time.Second)
+		if p.hasExited() && IsOllamaRunning(ctx, p.URL) {
+			log.Info("Reusing the ollama instance that started listening on the standard URL", svc1log.SafeParam("url", p.URL))
+			return nil, nil
+		}
+		return nil, err
+	}
+	return p, nil
+}
+
+// waitReady polls the server until it responds, it exits, the timeout elapses or the context is cancelled.
+func (p *Process) waitReady(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	backoff := minReadyBackoff
+	for {
+		if IsOllamaRunning(ctx, p.URL) {
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			return fmt.Errorf("ollama did not respond within %s%s", timeout, p.logHint())
+		}
+
+		select {
+		case <-p.exited:
+			return fmt.Errorf("ollama exited before it was ready: %v%s", p.err, p.logHint())
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(min(backoff, time.Until(deadline))):
+		}
+		backoff = min(backoff*2, maxReadyBackoff)
+	}
+}
+
+// Stop asks the server to shut down, and kills it if it has not exited after the timeout. Stopping a server that has
+// already exited is a no-op.
+func (p *Process) Stop(timeout time.Duration) error {
+	defer p.closeLog()
+	if p.hasExited() {
+		return nil
+	}
+	if err := terminate(p.cmd.Process); err != nil {
+		return fmt.Errorf("failed to stop ollama: %v", err)
+	}
+	select {
+	case <-p.exited:
+		return nil
+	case <-time.After(timeout):
+	}
+	if err := p.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to kill ollama: %v", err)
+	}
+	<-p.exited
+	return fmt.Errorf("ollama did not stop within %s and was killed", timeout)
+}
+
+// Detach leaves the server running after the CLI exits.
+func (p *Process) Detach() {
+	p.closeLog()
+}
+
+// Pid returns the process ID of the server.
+func (p *Process) Pid() int {
+	return p.cmd.Process.Pid
+}
+
+func (p *Process) hasExited() bool {
+	select {
+	case <-p.exited:
+		return true
+	default:
+		return false
+	}
+}
+
+func (p *Process) logHint() string {
+	if p.LogFile == "" {
+		return ""
+	}
+	return fmt.Sprintf(". See %s for details", p.LogFile)
+}
+
+func (p *Process) closeLog() {
+	if p.log != nil {
+		_ = p.log.Close()
+		p.log = nil
+	}
+}
+
+func openLogFile(path string) (*os.File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	return os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+}
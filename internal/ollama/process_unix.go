@@ -0,0 +1,20 @@
+//go:build !windows
+
+package ollama
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so that signals sent to the CLI's process group by the
+// terminal do not reach it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// terminate asks the process to shut down gracefully.
+func terminate(process *os.Process) error {
+	return process.Signal(syscall.SIGTERM)
+}
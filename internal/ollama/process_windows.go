@@ -0,0 +1,21 @@
+//go:build windows
+
+package ollama
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup starts the command in a new process group, so that console interrupts sent to the CLI do not reach
+// it.
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP}
+}
+
+// terminate stops the process. Windows cannot deliver a graceful shutdown signal to a process in another console
+// process group, so the process is killed.
+func terminate(process *os.Process) error {
+	return process.Kill()
+}
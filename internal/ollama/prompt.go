@@ -0,0 +1,76 @@
+package ollama
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ollama/ollama/api"
+)
+
+// Example is a few-shot example turn: a request and the answer the model is expected to give to it.
+type Example struct {
+	User      string
+	Assistant string
+}
+
+// Prompt is a conversation sent to the chat endpoint. System holds the role of the model and the rules its answers
+// must follow, Examples are shown to the model as earlier turns of the conversation, and User holds the request along
+// with the content it is about. Keeping the instructions in the system message makes it harder for the content to
+// override them. Canary, when set, is a token that the system message tells the model never to repeat; a response
+// containing it suggests that the model followed instructions embedded in the content, such as a request to print its
+// instructions, and is counted in the usage of the generator. Tokens lists the random values embedded in the messages,
+// such as the boundary that encloses the content and the canary. They differ with every prompt, so they are replaced
+// with placeholders in the cache key and in cached responses, which keeps the cache effective for the same content.
+type Prompt struct {
+	System   string
+	Examples []Example
+	User     string
+	Canary   string
+	Tokens   []string
+}
+
+// Messages returns the chat messages of the prompt, in the order they are sent.
+func (p Prompt) Messages() []api.Message {
+	messages := make([]api.Message, 0, 2*len(p.Examples)+2)
+	if p.System != "" {
+		messages = append(messages, api.Message{Role: "system", Content: p.System})
+	}
+	for _, example := range p.Examples {
+		messages = append(messages,
+			api.Message{Role: "user", Content: example.User},
+			api.Message{Role: "assistant", Content: example.Assistant},
+		)
+	}
+	return append(messages, api.Message{Role: "user", Content: p.User})
+}
+
+// String flattens the prompt into a single text with a header naming the role of each message, which is used to count
+// its tokens and to show it in plans.
+func (p Prompt) String() string {
+	messages := p.Messages()
+	parts := make([]string, 0, len(messages))
+	for _, message := range messages {
+		parts = append(parts, "### "+message.Role+"\n"+message.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// Mask replaces the tokens of the prompt in the text with placeholders that do not change between prompts.
+func (p Prompt) Mask(text string) string {
+	for i, token := range p.Tokens {
+		text = strings.ReplaceAll(text, token, tokenPlaceholder(i))
+	}
+	return text
+}
+
+// Unmask replaces the placeholders in the text with the tokens of the prompt, reversing Mask.
+func (p Prompt) Unmask(text string) string {
+	for i, token := range p.Tokens {
+		text = strings.ReplaceAll(text, tokenPlaceholder(i), token)
+	}
+	return text
+}
+
+func tokenPlaceholder(index int) string {
+	return fmt.Sprintf("\x00token-%d\x00", index)
+}
@@ -2,11 +2,18 @@ package ollama
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
 
+	"github.com/Method-Security/webassess/internal/cache"
+	"github.com/Method-Security/webassess/internal/progress"
 	"github.com/ollama/ollama/api"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
 )
 
 var ErrContextLengthExceeded = errors.New("context length exceeded")
@@ -25,61 +32,316 @@ func IsContextLengthError(err error) bool {
 	return false
 }
 
-// QueryModel queries the specified model with the given prompt using the Ollama SDK.
-func QueryModel(ctx context.Context, client *api.Client, model Model, prompt string) (string, error) {
+// GenerationOptions holds the model parameters sent with every generation request. Unset values fall back to the
+// defaults of the model.
+type GenerationOptions struct {
+	Temperature   *float64 `json:"temperature,omitempty" yaml:"temperature,omitempty" toml:"temperature,omitempty"`
+	TopP          *float64 `json:"topP,omitempty" yaml:"topP,omitempty" toml:"topP,omitempty"`
+	TopK          *int     `json:"topK,omitempty" yaml:"topK,omitempty" toml:"topK,omitempty"`
+	NumCtx        *int     `json:"numCtx,omitempty" yaml:"numCtx,omitempty" toml:"numCtx,omitempty"`
+	NumPredict    *int     `json:"numPredict,omitempty" yaml:"numPredict,omitempty" toml:"numPredict,omitempty"`
+	RepeatPenalty *float64 `json:"repeatPenalty,omitempty" yaml:"repeatPenalty,omitempty" toml:"repeatPenalty,omitempty"`
+	Seed          *int     `json:"seed,omitempty" yaml:"seed,omitempty" toml:"seed,omitempty"`
+}
+
+// Map converts the options into the options map understood by the Ollama API.
+func (o GenerationOptions) Map() map[string]interface{} {
+	options := map[string]interface{}{}
+	if o.Temperature != nil {
+		options["temperature"] = *o.Temperature
+	}
+	if o.TopP != nil {
+		options["top_p"] = *o.TopP
+	}
+	if o.TopK != nil {
+		options["top_k"] = *o.TopK
+	}
+	if o.NumCtx != nil {
+		options["num_ctx"] = *o.NumCtx
+	}
+	if o.NumPredict != nil {
+		options["num_predict"] = *o.NumPredict
+	}
+	if o.RepeatPenalty != nil {
+		options["repeat_penalty"] = *o.RepeatPenalty
+	}
+	if o.Seed != nil {
+		options["seed"] = *o.Seed
+	}
+	return options
+}
+
+// Usage accumulates the token counts and time spent across the generation requests of a Generator. Duration is the
+// wall time spent waiting on requests, while the remaining durations are those reported by Ollama. MaxSplitDepth is
+// the largest number of times a chunk was halved after exceeding the model's context length. CacheHits counts the
+// prompts answered from the cache, which are not included in Requests. CanaryLeaks counts the responses, including
+// those from the cache, that repeated the canary of their prompt.
+type Usage struct {
+	Requests           int
+	CacheHits          int
+	CanaryLeaks        int
+	PromptTokens       int
+	CompletionTokens   int
+	MaxSplitDepth      int
+	Duration           time.Duration
+	LoadDuration       time.Duration
+	PromptEvalDuration time.Duration
+	EvalDuration       time.Duration
+}
+
+// Generator runs generations against a single model with a fixed set of generation options, and keeps track of the
+// usage of every request it makes. When Cache is set, responses are looked up in and stored to the cache. KeepAlive
+// controls how long Ollama keeps the model loaded after each request, and defaults to the Ollama default when nil.
+// When Progress is set, the chunks analyzed and the tokens generated are reported to it.
+type Generator struct {
+	Client    *api.Client
+	Model     Model
+	Options   GenerationOptions
+	Cache     *cache.Cache
+	KeepAlive *time.Duration
+	Progress  *progress.Task
+
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewGenerator creates a Generator for the model using the provided Ollama client.
+func NewGenerator(client *api.Client, model Model, options GenerationOptions) *Generator {
+	return &Generator{
+		Client:  client,
+		Model:   model,
+		Options: options,
+	}
+}
+
+// QueryModel sends the prompt to the generator's model through the chat endpoint of the Ollama SDK and returns the
+// content of its answer.
+func (g *Generator) QueryModel(ctx context.Context, prompt Prompt) (string, error) {
+	var key string
+	if g.Cache != nil {
+		key = g.cacheKey(prompt)
+		if response, ok := g.Cache.Get(key); ok {
+			response = prompt.Unmask(response)
+			g.recordCacheHit()
+			g.checkCanary(prompt, response)
+			return response, nil
+		}
+	}
+
 	var result strings.Builder
 
-	req := &api.GenerateRequest{
-		Model:  model.Name,
-		Prompt: prompt,
+	req := &api.ChatRequest{
+		Model:    g.Model.Name,
+		Messages: prompt.Messages(),
+		Options:  g.Options.Map(),
+	}
+	if g.KeepAlive != nil {
+		req.KeepAlive = &api.Duration{Duration: *g.KeepAlive}
 	}
 
-	err := client.Generate(ctx, req, func(resp api.GenerateResponse) error {
-		result.WriteString(resp.Response)
+	start := time.Now()
+	var metrics api.Metrics
+	err := g.Client.Chat(ctx, req, func(resp api.ChatResponse) error {
+		result.WriteString(resp.Message.Content)
+		if resp.Done {
+			metrics = resp.Metrics
+		}
 		return nil
 	})
+	g.recordUsage(metrics, time.Since(start))
+	g.Progress.SetTokens(g.Usage().CompletionTokens)
 	if err != nil {
 		if IsContextLengthError(err) {
 			return "", ErrContextLengthExceeded
 		}
 		return "", fmt.Errorf("failed to generate response: %v", err)
 	}
+	g.checkCanary(prompt, result.String())
+
+	if g.Cache != nil {
+		if err := g.Cache.Put(key, prompt.Mask(result.String())); err != nil {
+			svc1log.FromContext(ctx).Warn("Failed to cache model response", svc1log.Stacktrace(err))
+		}
+	}
 
 	return result.String(), nil
 }
 
-type ModelPromptContentGenerator func(string) string
-type SplitOutputCombinerGenerator func(string, string) string
+// cacheKey identifies a response by the model, including its digest so that updating a model under the same name
+// invalidates its responses, the generation options and the messages of the prompt without their random tokens.
+func (g *Generator) cacheKey(prompt Prompt) string {
+	options, _ := json.Marshal(g.Options.Map())
+	messages, _ := json.Marshal(prompt.Messages())
+	return cache.Key(g.Model.Name, g.Model.Digest, string(options), prompt.Mask(string(messages)))
+}
+
+// WarmModel loads the model into memory without generating a response, and keeps it loaded for keepAlive after the
+// last request. A negative keepAlive keeps the model loaded until Ollama is stopped.
+func WarmModel(ctx context.Context, client *api.Client, model Model, keepAlive time.Duration) error {
+	req := &api.GenerateRequest{
+		Model:     model.Name,
+		KeepAlive: &api.Duration{Duration: keepAlive},
+	}
+	if err := client.Generate(ctx, req, func(api.GenerateResponse) error { return nil }); err != nil {
+		return fmt.Errorf("failed to load model: %v", err)
+	}
+	return nil
+}
+
+// Usage returns the usage accumulated by the generator so far.
+func (g *Generator) Usage() Usage {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.usage
+}
+
+func (g *Generator) recordUsage(metrics api.Metrics, duration time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.usage.Requests++
+	g.usage.PromptTokens += metrics.PromptEvalCount
+	g.usage.CompletionTokens += metrics.EvalCount
+	g.usage.Duration += duration
+	g.usage.LoadDuration += metrics.LoadDuration
+	g.usage.PromptEvalDuration += metrics.PromptEvalDuration
+	g.usage.EvalDuration += metrics.EvalDuration
+}
+
+func (g *Generator) recordCacheHit() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.usage.CacheHits++
+}
+
+// checkCanary counts the response as a canary leak if it contains the canary of the prompt.
+func (g *Generator) checkCanary(prompt Prompt, response string) {
+	if prompt.Canary == "" || !strings.Contains(response, prompt.Canary) {
+		return
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.usage.CanaryLeaks++
+}
+
+func (g *Generator) recordSplitDepth(depth int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if depth > g.usage.MaxSplitDepth {
+		g.usage.MaxSplitDepth = depth
+	}
+}
+
+// Chunk is a piece of the content being processed, along with its position. Index and Count describe the chunk's
+// position among the chunks the content was split into up front, and Depth is the number of times the chunk was
+// halved after exceeding the model's context length.
+type Chunk struct {
+	Content string
+	Index   int
+	Count   int
+	Depth   int
+}
+
+type ModelPromptContentGenerator func(Chunk) Prompt
+type SplitOutputCombinerGenerator func(string, string) Prompt
+
+// SplitOutputMerger combines the outputs of the two halves of a chunk in code rather than with the model, for answers
+// simple enough that the model could only get the combination wrong.
+type SplitOutputMerger func(string, string) string
+
+// splitOutputCombiner combines the outputs of the two halves of a chunk.
+type splitOutputCombiner func(ctx context.Context, firstOutput string, secondOutput string) (string, error)
+
+// synthesize returns a combiner that asks the model to combine the outputs with the prompt of the combiner.
+func (g *Generator) synthesize(combiner SplitOutputCombinerGenerator) splitOutputCombiner {
+	return func(ctx context.Context, firstOutput string, secondOutput string) (string, error) {
+		g.Progress.SetStatus("synthesizing")
+		return g.QueryModel(ctx, combiner(firstOutput, secondOutput))
+	}
+}
+
+// PartialError is returned when processing is interrupted, by the cancellation of the context or another error, after
+// some chunks were analyzed. Outputs holds the responses of the analyzed chunks, which have not been combined, so that
+// the work done so far is not lost.
+type PartialError struct {
+	Outputs []string
+	Err     error
+}
+
+func (e *PartialError) Error() string {
+	return fmt.Sprintf("%v (interrupted after %d analyzed chunks)", e.Err, len(e.Outputs))
+}
+
+func (e *PartialError) Unwrap() error {
+	return e.Err
+}
+
+// partialError wraps err in a PartialError if any chunks were analyzed.
+func partialError(err error, completed []string) error {
+	if len(completed) == 0 {
+		return err
+	}
+	return &PartialError{Outputs: completed, Err: err}
+}
 
 // ProcessContentRecursively processes the content recursively, splitting it if necessary.
 // The input always gets the same prompt generator call to ensure the instructions are consistent across splits.
-func ProcessContentRecursively(ctx context.Context, client *api.Client, model Model, input string, generator ModelPromptContentGenerator, combiner SplitOutputCombinerGenerator) (string, error) {
-	content := generator(input)
+func (g *Generator) ProcessContentRecursively(ctx context.Context, input string, generator ModelPromptContentGenerator, combiner SplitOutputCombinerGenerator) (string, error) {
+	return g.processContent(ctx, input, generator, g.synthesize(combiner))
+}
+
+// ProcessContentRecursivelyMerged processes the input like ProcessContentRecursively, but combines the outputs of the
+// halves of a chunk that exceeded the model's context length with merge instead of the model.
+func (g *Generator) ProcessContentRecursivelyMerged(ctx context.Context, input string, generator ModelPromptContentGenerator, merge SplitOutputMerger) (string, error) {
+	return g.processContent(ctx, input, generator, func(_ context.Context, firstOutput string, secondOutput string) (string, error) {
+		return merge(firstOutput, secondOutput), nil
+	})
+}
+
+func (g *Generator) processContent(ctx context.Context, input string, generator ModelPromptContentGenerator, combine splitOutputCombiner) (string, error) {
+	g.Progress.AddTotal(1)
+	completed := []string{}
+	result, err := g.processChunk(ctx, Chunk{Content: input, Index: 0, Count: 1}, generator, combine, &completed)
+	if err != nil {
+		return "", partialError(err, completed)
+	}
+	return result, nil
+}
+
+// processChunk analyzes the chunk, splitting it in half when it exceeds the model's context length. The response of
+// every chunk that was analyzed is appended to completed.
+func (g *Generator) processChunk(ctx context.Context, chunk Chunk, generator ModelPromptContentGenerator, combine splitOutputCombiner, completed *[]string) (string, error) {
+	g.recordSplitDepth(chunk.Depth)
+	g.Progress.SetStatus("analyzing")
+	prompt := generator(chunk)
 	// Attempt to query the model
-	response, err := QueryModel(ctx, client, model, content)
+	response, err := g.QueryModel(ctx, prompt)
 	if err != nil {
-		if IsContextLengthError(err) && len(input) > 0 {
+		if IsContextLengthError(err) && len(chunk.Content) > 0 {
 			// If context length is exceeded, split the content and process each half
-			mid := len(input) / 2
-			leftInput := input[:mid]
-			rightInput := input[mid:]
+			mid := len(chunk.Content) / 2
+			leftChunk := chunk
+			leftChunk.Content = chunk.Content[:mid]
+			leftChunk.Depth++
+			rightChunk := chunk
+			rightChunk.Content = chunk.Content[mid:]
+			rightChunk.Depth++
+			// The chunk is replaced by its two halves
+			g.Progress.AddTotal(1)
 
 			// Recursively process the left half
-			leftResult, errLeft := ProcessContentRecursively(ctx, client, model, leftInput, generator, combiner)
+			leftResult, errLeft := g.processChunk(ctx, leftChunk, generator, combine, completed)
 			if errLeft != nil {
 				return "", errLeft
 			}
 
 			// Recursively process the right half
-			rightResult, errRight := ProcessContentRecursively(ctx, client, model, rightInput, generator, combiner)
+			rightResult, errRight := g.processChunk(ctx, rightChunk, generator, combine, completed)
 			if errRight != nil {
 				return "", errRight
 			}
 
 			// Combine the results
-			combinedPrompt := combiner(leftResult, rightResult)
-
-			finalResult, err := QueryModel(ctx, client, model, combinedPrompt)
+			finalResult, err := combine(ctx, leftResult, rightResult)
 			if err != nil {
 				return "", err
 			}
@@ -90,5 +352,73 @@ func ProcessContentRecursively(ctx context.Context, client *api.Client, model Mo
 		return "", err
 	}
 
+	g.Progress.Advance(1)
+	*completed = append(*completed, response)
 	return response, nil
 }
+
+// ProcessContentInChunks processes each chunk with ProcessContentRecursively, then combines the results of adjacent
+// chunks pairwise until a single result remains.
+func (g *Generator) ProcessContentInChunks(ctx context.Context, chunks []string, generator ModelPromptContentGenerator, combiner SplitOutputCombinerGenerator) (string, error) {
+	if len(chunks) == 0 {
+		return g.ProcessContentRecursively(ctx, "", generator, combiner)
+	}
+
+	g.Progress.AddTotal(int64(len(chunks)))
+	completed := []string{}
+	results := make([]string, 0, len(chunks))
+	for i, content := range chunks {
+		result, err := g.processChunk(ctx, Chunk{Content: content, Index: i, Count: len(chunks)}, generator, g.synthesize(combiner), &completed)
+		if err != nil {
+			return "", partialError(err, completed)
+		}
+		results = append(results, result)
+	}
+
+	for len(results) > 1 {
+		g.Progress.SetStatus("synthesizing")
+		combined := make([]string, 0, (len(results)+1)/2)
+		for i := 0; i < len(results); i += 2 {
+			if i+1 == len(results) {
+				combined = append(combined, results[i])
+				continue
+			}
+			result, err := g.QueryModel(ctx, combiner(results[i], results[i+1]))
+			if err != nil {
+				return "", partialError(err, completed)
+			}
+			combined = append(combined, result)
+		}
+		results = combined
+	}
+
+	return results[0], nil
+}
+
+// SplitContent splits the input into chunks of at most size bytes. Chunks are broken at the last newline in the second
+// half of the window where possible, and never in the middle of a UTF-8 encoded character.
+func SplitContent(input string, size int) []string {
+	if size <= 0 || len(input) <= size {
+		return []string{input}
+	}
+
+	chunks := []string{}
+	for len(input) > size {
+		end := size
+		if newline := strings.LastIndex(input[:size], "\n"); newline >= size/2 {
+			end = newline + 1
+		}
+		for end > 0 && !utf8.RuneStart(input[end]) {
+			end--
+		}
+		if end == 0 {
+			end = size
+		}
+		chunks = append(chunks, input[:end])
+		input = input[end:]
+	}
+	if len(input) > 0 {
+		chunks = append(chunks, input)
+	}
+	return chunks
+}
@@ -4,31 +4,34 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
-	"os/exec"
-	"time"
 
-	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+	"github.com/Method-Security/webassess/internal/progress"
+	"github.com/ollama/ollama/api"
 )
 
 const OllamaStandardBaseURL = "http://127.0.0.1:11434"
 
-var logger = svc1log.FromContext(context.Background())
-
 type Model struct {
 	Name              string `json:"name"`
 	ModifiedAt        string `json:"modified_at"`
 	Size              int64  `json:"size"`
+	Digest            string `json:"digest"`
 	ContextWindowSize int    `json:"context_window_size"`
 }
 
-func GetAvailableOllamaModels(url string) ([]Model, error) {
+func GetAvailableOllamaModels(ctx context.Context, url string) ([]Model, error) {
 	tagsURL := url + "/api/tags"
 
-	resp, err := http.Get(tagsURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagsURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to make request: %v", err)
 	}
@@ -64,19 +67,8 @@ func GetAvailableOllamaModels(url string) ([]Model, error) {
 	return result.Models, nil
 }
 
-func IsAllowedModel(modelName string) bool {
-	var isAllowed bool = false
-	for _, allowedModelName := range AllowedOllamaModels {
-		if allowedModelName == modelName {
-			isAllowed = true
-			break
-		}
-	}
-	return isAllowed
-}
-
-func ModelReady(url string, modelName string) bool {
-	models, err := GetAvailableOllamaModels(url)
+func ModelReady(ctx context.Context, url string, modelName string) bool {
+	models, err := GetAvailableOllamaModels(ctx, url)
 	if err != nil {
 		return false
 	}
@@ -90,8 +82,8 @@ func ModelReady(url string, modelName string) bool {
 	return false
 }
 
-func GetModel(url string, modelName string) (Model, error) {
-	models, err := GetAvailableOllamaModels(url)
+func GetModel(ctx context.Context, url string, modelName string) (Model, error) {
+	models, err := GetAvailableOllamaModels(ctx, url)
 	if err != nil {
 		return Model{}, err
 	}
@@ -105,7 +97,9 @@ func GetModel(url string, modelName string) (Model, error) {
 	return Model{}, fmt.Errorf("model not found")
 }
 
-func DownloadOllamaModel(modelName string, url string) error {
+// DownloadOllamaModel pulls the model from the Ollama library, reporting the download progress to the reporter if it is
+// not nil. Cancelling the context stops the download.
+func DownloadOllamaModel(ctx context.Context, modelName string, url string, reporter progress.Reporter) error {
 	pullURL := url + "/api/pull"
 
 	requestBody, err := json.Marshal(map[string]string{
@@ -115,7 +109,12 @@ func DownloadOllamaModel(modelName string, url string) error {
 		return fmt.Errorf("failed to create request body: %v", err)
 	}
 
-	resp, err := http.Post(pullURL, "application/json", bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, pullURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return fmt.Errorf("failed to make request: %v", err)
 	}
@@ -134,41 +133,44 @@ func DownloadOllamaModel(modelName string, url string) error {
 		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
 	}
 
-	// Create a channel to send progress updates
-	progress := make(chan string)
-
-	// Start a goroutine to read and process the response body
-	go func() {
-		defer close(progress)
-
-		decoder := json.NewDecoder(resp.Body)
-		for {
-			var result map[string]interface{}
-			if err := decoder.Decode(&result); err != nil {
-				if err == io.EOF {
-					break
-				}
-				progress <- fmt.Sprintf("Error decoding response: %v", err)
-				return
-			}
-
-			if status, ok := result["status"].(string); ok {
-				progress <- status
+	task := progress.Start(reporter, "pull "+modelName, progress.UnitBytes)
+	decoder := json.NewDecoder(resp.Body)
+	for {
+		var update struct {
+			api.ProgressResponse
+			Error string `json:"error"`
+		}
+		if err := decoder.Decode(&update); err != nil {
+			if err == io.EOF {
+				break
 			}
+			err = fmt.Errorf("error decoding response: %v", err)
+			task.Done(err)
+			return err
 		}
-	}()
-
-	// Print progress updates
-	for status := range progress {
-		logger.Info(status)
+		// Errors are reported in the stream with a 200 OK status, for instance when the model does not exist
+		if update.Error != "" {
+			err := errors.New(update.Error)
+			task.Done(err)
+			return err
+		}
+		task.Update(update.Status, update.Completed, update.Total)
 	}
+	task.Done(nil)
 
 	return nil
 }
 
-func IsOllamaRunning(ollamaBaseURL string) bool {
+// IsOllamaRunning returns true if an Ollama server responds on the base URL. The request times out quickly, so that a
+// port held by an unresponsive process is not mistaken for a running server and does not block the CLI.
+func IsOllamaRunning(ctx context.Context, ollamaBaseURL string) bool {
 	tagsURL := ollamaBaseURL + "/api/tags"
-	resp, err := http.Get(tagsURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tagsURL, nil)
+	if err != nil {
+		return false
+	}
+	client := &http.Client{Timeout: runningCheckTimeout}
+	resp, err := client.Do(req)
 	if err != nil {
 		return false
 	}
@@ -184,15 +186,3 @@ func IsOllamaRunning(ollamaBaseURL string) bool {
 	}()
 	return resp.StatusCode == http.StatusOK
 }
-
-func StartOllama() error {
-	cmd := exec.Command("ollama", "serve")
-	err := cmd.Start()
-	if err != nil {
-		return fmt.Errorf("failed to start Ollama: %v", err)
-	}
-
-	// Wait for Ollama to start
-	time.Sleep(5 * time.Second)
-	return nil
-}
@@ -2,6 +2,7 @@ package ollama
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -17,7 +18,7 @@ type TokenCountResponse struct {
 	Tokens int `json:"tokens"`
 }
 
-func CountTokens(url string, model Model, prompt string) (int, error) {
+func CountTokens(ctx context.Context, url string, model Model, prompt string) (int, error) {
 	tokenURL := url + "/api/tokens"
 
 	requestBody, err := json.Marshal(TokenCountRequest{
@@ -28,7 +29,12 @@ func CountTokens(url string, model Model, prompt string) (int, error) {
 		return 0, err
 	}
 
-	resp, err := http.Post(tokenURL, "application/json", bytes.NewBuffer(requestBody))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, bytes.NewBuffer(requestBody))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return 0, err
 	}
@@ -43,6 +49,10 @@ func CountTokens(url string, model Model, prompt string) (int, error) {
 		}
 	}()
 
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
 		return 0, err
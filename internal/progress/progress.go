@@ -0,0 +1,118 @@
+// Package progress reports the progress of long running tasks, such as model pulls and chunked analyses. Tasks send
+// events to a Reporter, which renders them as a progress bar on a terminal or as structured log events otherwise.
+package progress
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/mattn/go-isatty"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// Unit is the unit of the completed and total amounts of a task.
+type Unit string
+
+const (
+	// UnitBytes measures downloads and uploads.
+	UnitBytes Unit = "bytes"
+	// UnitChunks measures the chunks of content analyzed by the model.
+	UnitChunks Unit = "chunks"
+)
+
+// Event is a progress update of a task. Total is 0 while it is unknown, and Tokens is the number of tokens generated so
+// far. Done is set on the last event of the task, along with Err if the task failed.
+type Event struct {
+	Task      string
+	Status    string
+	Unit      Unit
+	Completed int64
+	Total     int64
+	Tokens    int
+	Done      bool
+	Err       error
+}
+
+// Reporter receives the progress events of tasks.
+type Reporter interface {
+	Report(event Event)
+}
+
+// New returns the reporter for the CLI: nil when quiet is set, a progress bar written to out when it is a terminal,
+// and structured log events otherwise.
+func New(ctx context.Context, out *os.File, quiet bool) Reporter {
+	if quiet {
+		return nil
+	}
+	if isatty.IsTerminal(out.Fd()) || isatty.IsCygwinTerminal(out.Fd()) {
+		return NewBar(out)
+	}
+	return NewLog(svc1log.FromContext(ctx))
+}
+
+// Task tracks the progress of a single task and reports every change to its reporter. A nil task, as returned by Start
+// for a nil reporter, ignores every update, so that callers do not need to check whether progress is reported.
+type Task struct {
+	mu       sync.Mutex
+	reporter Reporter
+	event    Event
+}
+
+// Start starts a task reported to the reporter, or returns nil if the reporter is nil.
+func Start(reporter Reporter, name string, unit Unit) *Task {
+	if reporter == nil {
+		return nil
+	}
+	return &Task{reporter: reporter, event: Event{Task: name, Unit: unit}}
+}
+
+// SetStatus describes what the task is currently doing.
+func (t *Task) SetStatus(status string) {
+	t.update(func(event *Event) { event.Status = status })
+}
+
+// Update sets the status along with the completed and total amounts, as reported by Ollama while pulling a layer.
+func (t *Task) Update(status string, completed int64, total int64) {
+	t.update(func(event *Event) {
+		event.Status = status
+		event.Completed = completed
+		event.Total = total
+	})
+}
+
+// AddTotal adds to the total amount of the task, which grows when an analysis has to split a chunk.
+func (t *Task) AddTotal(delta int64) {
+	t.update(func(event *Event) { event.Total += delta })
+}
+
+// Advance adds to the completed amount of the task.
+func (t *Task) Advance(delta int64) {
+	t.update(func(event *Event) { event.Completed += delta })
+}
+
+// SetTokens sets the number of tokens generated so far.
+func (t *Task) SetTokens(tokens int) {
+	t.update(func(event *Event) { event.Tokens = tokens })
+}
+
+// Done reports the end of the task, which failed if err is not nil. Later updates are ignored.
+func (t *Task) Done(err error) {
+	t.update(func(event *Event) {
+		event.Done = true
+		event.Err = err
+	})
+}
+
+func (t *Task) update(apply func(*Event)) {
+	if t == nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.event.Done {
+		return
+	}
+	apply(&t.event)
+	t.reporter.Report(t.event)
+}
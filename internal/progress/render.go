@@ -0,0 +1,157 @@
+package progress
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+const (
+	barWidth       = 30
+	redrawInterval = 100 * time.Millisecond
+)
+
+// Bar renders the progress of the current task as a single line that is redrawn in place, for interactive terminals.
+// Redraws are throttled, except when the status changes or the task ends.
+type Bar struct {
+	mu     sync.Mutex
+	out    io.Writer
+	task   string
+	status string
+	drawn  time.Time
+	active bool
+}
+
+// NewBar creates a progress bar written to out.
+func NewBar(out io.Writer) *Bar {
+	return &Bar{out: out}
+}
+
+// Report redraws the bar with the event.
+func (b *Bar) Report(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	sameTask := b.active && event.Task == b.task
+	if sameTask && !event.Done && event.Status == b.status && time.Since(b.drawn) < redrawInterval {
+		return
+	}
+	// Leave the line of another task as it was last drawn
+	if b.active && !sameTask {
+		fmt.Fprintln(b.out)
+	}
+	b.task, b.status, b.drawn, b.active = event.Task, event.Status, time.Now(), true
+	fmt.Fprintf(b.out, "\r\033[K%s", formatBarLine(event))
+	if event.Done {
+		fmt.Fprintln(b.out)
+		b.active = false
+	}
+}
+
+func formatBarLine(event Event) string {
+	parts := []string{event.Task}
+	if event.Total > 0 {
+		completed := min(event.Completed, event.Total)
+		filled := int(completed * barWidth / event.Total)
+		parts = append(parts, fmt.Sprintf("[%s%s] %3d%%", strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled), completed*100/event.Total))
+	}
+	if amount := formatAmount(event); amount != "" {
+		parts = append(parts, amount)
+	}
+	if event.Tokens > 0 {
+		parts = append(parts, fmt.Sprintf("%d tokens", event.Tokens))
+	}
+	switch {
+	case event.Done && event.Err != nil:
+		parts = append(parts, "failed: "+event.Err.Error())
+	case event.Done:
+		parts = append(parts, "done")
+	case event.Status != "":
+		parts = append(parts, event.Status)
+	}
+	return strings.Join(parts, "  ")
+}
+
+// Log reports progress as structured log events, for output that is not interactive, such as CI logs. An event is
+// logged when the status of a task changes, for every 10% of its total and when it ends.
+type Log struct {
+	mu     sync.Mutex
+	logger svc1log.Logger
+	last   map[string]logState
+}
+
+type logState struct {
+	status string
+	decile int64
+}
+
+// NewLog creates a reporter that logs to the logger.
+func NewLog(logger svc1log.Logger) *Log {
+	return &Log{logger: logger, last: map[string]logState{}}
+}
+
+// Report logs the event if it is a significant change of its task.
+func (l *Log) Report(event Event) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	state := logState{status: event.Status, decile: -1}
+	if event.Total > 0 {
+		state.decile = min(event.Completed, event.Total) * 10 / event.Total
+	}
+	if last, ok := l.last[event.Task]; ok && last == state && !event.Done {
+		return
+	}
+	l.last[event.Task] = state
+
+	params := []svc1log.Param{
+		svc1log.SafeParam("task", event.Task),
+		svc1log.SafeParam("unit", string(event.Unit)),
+		svc1log.SafeParam("completed", event.Completed),
+		svc1log.SafeParam("total", event.Total),
+		svc1log.SafeParam("tokens", event.Tokens),
+	}
+	switch {
+	case event.Done && event.Err != nil:
+		delete(l.last, event.Task)
+		l.logger.Warn("Task failed", append(params, svc1log.Stacktrace(event.Err))...)
+	case event.Done:
+		delete(l.last, event.Task)
+		l.logger.Info("Task finished", params...)
+	default:
+		l.logger.Info("Task progress", append(params, svc1log.SafeParam("status", event.Status))...)
+	}
+}
+
+func formatAmount(event Event) string {
+	if event.Total <= 0 && event.Completed <= 0 {
+		return ""
+	}
+	if event.Unit == UnitBytes {
+		if event.Total <= 0 {
+			return formatBytes(event.Completed)
+		}
+		return formatBytes(event.Completed) + "/" + formatBytes(event.Total)
+	}
+	if event.Total <= 0 {
+		return fmt.Sprintf("%d %s", event.Completed, event.Unit)
+	}
+	return fmt.Sprintf("%d/%d %s", event.Completed, event.Total, event.Unit)
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
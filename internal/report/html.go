@@ -0,0 +1,166 @@
+package report
+
+import (
+	"bytes"
+	"html/template"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+)
+
+const htmlTemplate = `<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>webassess report</title>
+<style>
+body { font-family: -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; margin: 2rem auto; max-width: 72rem; color: #1f2328; }
+h1, h2, h3 { margin-top: 2rem; }
+table { border-collapse: collapse; margin: 1rem 0; width: 100%; }
+th, td { border: 1px solid #d0d7de; padding: 0.4rem 0.6rem; text-align: left; vertical-align: top; }
+th { background: #f6f8fa; }
+pre { background: #f6f8fa; padding: 0.8rem; overflow-x: auto; white-space: pre-wrap; word-break: break-all; }
+.severity { font-weight: 600; padding: 0.1rem 0.4rem; border-radius: 0.3rem; color: #fff; }
+.severity-critical { background: #8b0000; }
+.severity-high { background: #cf222e; }
+.severity-medium { background: #bc4c00; }
+.severity-low { background: #9a6700; }
+.severity-info { background: #57606a; }
+.errors { color: #cf222e; }
+.incomplete { color: #9a6700; }
+.finding { border-left: 4px solid #d0d7de; padding-left: 1rem; margin: 1rem 0; }
+</style>
+</head>
+<body>
+<h1>webassess report</h1>
+<table>
+<tr><th>Tool version</th><td>{{ .ToolVersion }}</td></tr>
+<tr><th>Model</th><td>{{ .Model }} ({{ .ModelSize }})</td></tr>
+{{- if .Context }}
+<tr><th>Context window</th><td>{{ .Context }}</td></tr>
+{{- end }}
+<tr><th>Started</th><td>{{ .StartedAt }}</td></tr>
+<tr><th>Completed</th><td>{{ .CompletedAt }}</td></tr>
+<tr><th>Status</th><td>{{ .Status }}</td></tr>
+{{- if .Error }}
+<tr><th>Error</th><td class="errors">{{ .Error }}</td></tr>
+{{- end }}
+{{- with .Metrics }}
+<tr><th>Generations</th><td>{{ .Generations }} ({{ .CacheHits }} cached, max split depth {{ .MaxSplitDepth }})</td></tr>
+<tr><th>Tokens</th><td>{{ .PromptTokens }} prompt, {{ .OutputTokens }} output</td></tr>
+<tr><th>Wall time</th><td>{{ duration .WallTimeMs }} ({{ duration .GenerationTimeMs }} generating)</td></tr>
+<tr><th>Throughput</th><td>{{ printf "%.1f" .PromptTokensPerSecond }} prompt tokens/s, {{ printf "%.1f" .OutputTokensPerSecond }} output tokens/s</td></tr>
+{{- end }}
+</table>
+<p>{{ range .Totals }}<span class="severity {{ severityClass .Severity }}">{{ .Severity }} {{ .Count }}</span> {{ end }}</p>
+{{- range .Targets }}
+<h2>{{ .Target }}</h2>
+{{- if .Incomplete }}
+<p class="incomplete"><strong>Incomplete:</strong> {{ .Incomplete }}</p>
+{{- end }}
+{{- if .Ensemble }}
+<p><strong>Ensemble:</strong> {{ .Ensemble }}</p>
+{{- end }}
+{{- if .Cascade }}
+<p><strong>Cascade:</strong> {{ .Cascade }}</p>
+{{- end }}
+{{- if .Fetch }}
+<table>
+<tr><th>Final URL</th><td>{{ .Fetch.FinalUrl }}</td></tr>
+<tr><th>Status code</th><td>{{ .Fetch.StatusCode }}</td></tr>
+<tr><th>Content type</th><td>{{ deref .Fetch.ContentType }}</td></tr>
+<tr><th>Content length</th><td>{{ .Fetch.ContentLength }}</td></tr>
+<tr><th>SHA-256</th><td><code>{{ .Fetch.ContentSha256 }}</code></td></tr>
+</table>
+{{- end }}
+{{- if .Errors }}
+<h3>Errors</h3>
+<ul class="errors">
+{{- range .Errors }}
+<li>{{ . }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- with .Assessment }}
+<h3>Summary</h3>
+<p>{{ .CodeSummary }}</p>
+{{- if .VulnerabilitiesSummary }}
+<p><strong>Vulnerabilities:</strong> {{ .VulnerabilitiesSummary }}</p>
+{{- end }}
+{{- if .SensitiveDataSummary }}
+<p><strong>Sensitive data:</strong> {{ .SensitiveDataSummary }}</p>
+{{- end }}
+{{- end }}
+<h3>Findings</h3>
+{{- if .Findings }}
+{{- $ensemble := .Ensemble }}
+<table>
+<tr><th>Severity</th><th>Category</th><th>Title</th><th>Location</th><th>Verification</th>{{ if $ensemble }}<th>Agreement</th>{{ end }}</tr>
+{{- range .Findings }}
+<tr><td><span class="severity {{ severityClass .Severity }}">{{ .Severity }}</span></td><td>{{ category .Category }}</td><td>{{ .Title }}</td><td>{{ location . }}</td><td>{{ verification . }}</td>{{ if $ensemble }}<td>{{ agreement . }}</td>{{ end }}</tr>
+{{- end }}
+</table>
+{{- range .Findings }}
+<div class="finding">
+<h4><span class="severity {{ severityClass .Severity }}">{{ .Severity }}</span> {{ .Title }}</h4>
+<p>{{ .Description }}</p>
+{{- if .Evidence }}
+<pre><code>{{ deref .Evidence }}</code></pre>
+{{- end }}
+</div>
+{{- end }}
+{{- else }}
+<p>No findings.</p>
+{{- end }}
+{{- with .Diff }}
+<h3>Changes since baseline</h3>
+<p>{{ if .Error }}The findings could not be compared: {{ deref .Error }}.{{ else if not .InBaseline }}The target was not present in the baseline.{{ else if .ContentChanged }}The content changed since the baseline.{{ else }}The content did not change since the baseline.{{ end }}
+New: {{ len .NewFindings }}, resolved: {{ len .ResolvedFindings }}, unchanged: {{ len .UnchangedFindings }}</p>
+<ul>
+{{- range .NewFindings }}
+<li>New <span class="severity {{ severityClass .Severity }}">{{ .Severity }}</span> {{ .Title }}</li>
+{{- end }}
+{{- range .ResolvedFindings }}
+<li>Resolved <span class="severity {{ severityClass .Severity }}">{{ .Severity }}</span> {{ .Title }}</li>
+{{- end }}
+</ul>
+{{- end }}
+{{- if .Discarded }}
+<h3>Discarded findings</h3>
+<table>
+<tr><th>Title</th><th>Reason</th></tr>
+{{- range .Discarded }}
+<tr><td>{{ .Title }}</td><td>{{ with .Verification }}{{ deref .Detail }}{{ end }}</td></tr>
+{{- end }}
+</table>
+{{- end }}
+{{- end }}
+</body>
+</html>
+`
+
+// RenderHTML renders the reports as a single self-contained HTML document with inline styles and no external assets.
+func RenderHTML(reports []webassess.UrlReport, metadata Metadata) ([]byte, error) {
+	tmpl, err := template.New("html").Funcs(template.FuncMap{
+		"deref":         deref,
+		"category":      categoryLabel,
+		"location":      locationLabel,
+		"verification":  verificationLabel,
+		"agreement":     agreementLabel,
+		"severityClass": severityClass,
+		"duration":      durationLabel,
+	}).Parse(htmlTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newReportView(reports, metadata)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func severityClass(severity webassess.Severity) string {
+	return "severity-" + strings.ToLower(string(severity))
+}
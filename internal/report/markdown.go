@@ -0,0 +1,150 @@
+package report
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+)
+
+const markdownTemplate = `# webassess report
+
+| Parameter | Value |
+| --- | --- |
+| Tool version | {{ cell .ToolVersion }} |
+| Model | {{ cell .Model }} ({{ .ModelSize }}) |
+{{- if .Context }}
+| Context window | {{ .Context }} |
+{{- end }}
+| Started | {{ .StartedAt }} |
+| Completed | {{ cell .CompletedAt }} |
+| Status | {{ .Status }} |
+{{- if .Error }}
+| Error | {{ cell .Error }} |
+{{- end }}
+{{- with .Metrics }}
+| Generations | {{ .Generations }} ({{ .CacheHits }} cached, max split depth {{ .MaxSplitDepth }}) |
+| Tokens | {{ .PromptTokens }} prompt, {{ .OutputTokens }} output |
+| Wall time | {{ duration .WallTimeMs }} ({{ duration .GenerationTimeMs }} generating) |
+| Throughput | {{ printf "%.1f" .PromptTokensPerSecond }} prompt tokens/s, {{ printf "%.1f" .OutputTokensPerSecond }} output tokens/s |
+{{- end }}
+
+**Findings:**{{ range .Totals }} {{ .Severity }} {{ .Count }}{{ end }}
+{{ range .Targets }}
+## {{ .Target }}
+{{ if .Incomplete }}
+**Incomplete:** {{ .Incomplete }}
+{{ end }}
+{{- if .Ensemble }}
+**Ensemble:** {{ .Ensemble }}
+{{ end }}
+{{- if .Cascade }}
+**Cascade:** {{ .Cascade }}
+{{ end }}
+{{- if .Fetch }}
+| Fetch | Value |
+| --- | --- |
+| Final URL | {{ cell .Fetch.FinalUrl }} |
+| Status code | {{ .Fetch.StatusCode }} |
+| Content type | {{ cell (deref .Fetch.ContentType) }} |
+| Content length | {{ .Fetch.ContentLength }} |
+| SHA-256 | ` + "`{{ .Fetch.ContentSha256 }}`" + ` |
+{{ end }}
+{{- if .Errors }}
+### Errors
+{{ range .Errors }}
+- {{ . }}
+{{- end }}
+{{ end }}
+{{- with .Assessment }}
+### Summary
+
+{{ .CodeSummary }}
+{{ if .VulnerabilitiesSummary }}
+**Vulnerabilities:** {{ .VulnerabilitiesSummary }}
+{{ end }}
+{{- if .SensitiveDataSummary }}
+**Sensitive data:** {{ .SensitiveDataSummary }}
+{{ end }}
+{{- end }}
+### Findings
+{{ if .Findings }}
+{{- $ensemble := .Ensemble }}
+| Severity | Category | Title | Location | Verification |{{ if $ensemble }} Agreement |{{ end }}
+| --- | --- | --- | --- | --- |{{ if $ensemble }} --- |{{ end }}
+{{- range .Findings }}
+| {{ .Severity }} | {{ category .Category }} | {{ cell .Title }} | {{ location . }} | {{ verification . }} |{{ if $ensemble }} {{ agreement . }} |{{ end }}
+{{- end }}
+{{ range .Findings }}
+#### {{ .Severity }}: {{ .Title }}
+
+{{ .Description }}
+{{ if .Evidence }}
+{{ fence (deref .Evidence) }}
+{{ end }}
+{{- end }}
+{{- else }}
+No findings.
+{{ end }}
+{{- with .Diff }}
+### Changes since baseline
+
+{{ if .Error }}The findings could not be compared: {{ deref .Error }}.{{ else if not .InBaseline }}The target was not present in the baseline.{{ else if .ContentChanged }}The content changed since the baseline.{{ else }}The content did not change since the baseline.{{ end }}
+New: {{ len .NewFindings }}, resolved: {{ len .ResolvedFindings }}, unchanged: {{ len .UnchangedFindings }}
+{{ range .NewFindings }}
+- New {{ .Severity }}: {{ .Title }}
+{{- end }}
+{{- range .ResolvedFindings }}
+- Resolved {{ .Severity }}: {{ .Title }}
+{{- end }}
+{{ end }}
+{{- if .Discarded }}
+### Discarded findings
+
+| Title | Reason |
+| --- | --- |
+{{- range .Discarded }}
+| {{ cell .Title }} | {{ with .Verification }}{{ cell (deref .Detail) }}{{ end }} |
+{{- end }}
+{{ end }}
+{{- end }}`
+
+// RenderMarkdown renders the reports as a single self-contained Markdown document.
+func RenderMarkdown(reports []webassess.UrlReport, metadata Metadata) ([]byte, error) {
+	tmpl, err := template.New("markdown").Funcs(template.FuncMap{
+		"cell":         markdownCell,
+		"fence":        markdownFence,
+		"deref":        deref,
+		"category":     categoryLabel,
+		"location":     locationLabel,
+		"verification": verificationLabel,
+		"agreement":    agreementLabel,
+		"duration":     durationLabel,
+	}).Parse(markdownTemplate)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, newReportView(reports, metadata)); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// markdownCell escapes a value so that it can be placed in a single table cell.
+func markdownCell(value string) string {
+	value = strings.ReplaceAll(value, "|", "\\|")
+	value = strings.ReplaceAll(value, "\r", "")
+	return strings.ReplaceAll(value, "\n", "<br>")
+}
+
+// markdownFence wraps a snippet in a code fence long enough that backticks in the snippet cannot close it.
+func markdownFence(value string) string {
+	fence := "```"
+	for strings.Contains(value, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + value + "\n" + fence
+}
@@ -0,0 +1,54 @@
+package report
+
+import (
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/url"
+)
+
+// HasFindingAtOrAbove returns true if the content contains a finding whose severity is at or above the threshold. For
+// URL reports every reported finding is considered, while for diff reports only new findings are considered so that
+// recurring runs only fail on what changed.
+func HasFindingAtOrAbove(content any, threshold webassess.Severity) bool {
+	for _, finding := range policyFindings(content) {
+		if url.SeverityRank(finding.Severity) >= url.SeverityRank(threshold) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasErrors returns true if any URL report in the content recorded an error during its assessment, or was cancelled or
+// timed out before it completed.
+func HasErrors(content any) bool {
+	for _, report := range Reports(content) {
+		if len(report.Errors) > 0 || incompleteReason(report.Status) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+func policyFindings(content any) []*webassess.Finding {
+	var diffReport *webassess.DiffReport
+	switch c := content.(type) {
+	case webassess.DiffReport:
+		diffReport = &c
+	case *webassess.DiffReport:
+		diffReport = c
+	}
+	if diffReport != nil {
+		findings := []*webassess.Finding{}
+		for _, target := range diffReport.Targets {
+			findings = append(findings, target.NewFindings...)
+		}
+		return findings
+	}
+
+	findings := []*webassess.Finding{}
+	for _, report := range Reports(content) {
+		if report.Assessment != nil {
+			findings = append(findings, report.Assessment.Findings...)
+		}
+	}
+	return findings
+}
@@ -0,0 +1,113 @@
+// Package report renders webassess reports in the output formats that are not handled by the shared Method writer
+// package, such as SARIF and the human readable Markdown and HTML reports. The writer package remains responsible for
+// the signal, json and yaml formats.
+package report
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/url"
+	"github.com/palantir/pkg/datetime"
+)
+
+// Format is an output format rendered by this package.
+type Format string
+
+const (
+	SARIF    Format = "sarif"
+	MARKDOWN Format = "markdown"
+	HTML     Format = "html"
+)
+
+// Formats returns all of the formats rendered by this package.
+func Formats() []Format {
+	return []Format{SARIF, MARKDOWN, HTML}
+}
+
+// IsFormat returns true if the provided output format is rendered by this package rather than the writer package.
+func IsFormat(output string) bool {
+	for _, format := range Formats() {
+		if strings.EqualFold(string(format), output) {
+			return true
+		}
+	}
+	return false
+}
+
+// Metadata describes the run that produced the reports being rendered. Metrics are the usage metrics of the run; when
+// nil, they are aggregated from the reports.
+type Metadata struct {
+	ToolVersion  string
+	Model        ollama.Model
+	StartedAt    datetime.DateTime
+	CompletedAt  *datetime.DateTime
+	Status       int
+	ErrorMessage *string
+	Metrics      *webassess.UsageMetrics
+}
+
+// Write renders the content in the requested format and writes it to the file path, or to STDOUT if the path is nil.
+func Write(content any, format Format, filePath *string, metadata Metadata) error {
+	data, err := Render(content, format, metadata)
+	if err != nil {
+		return err
+	}
+	return writeToFileOrStdout(data, filePath)
+}
+
+// Render renders the content in the requested format.
+func Render(content any, format Format, metadata Metadata) ([]byte, error) {
+	reports := Reports(content)
+	if metadata.Metrics == nil {
+		metadata.Metrics = RunMetrics(reports)
+	}
+
+	switch Format(strings.ToLower(string(format))) {
+	case SARIF:
+		return RenderSARIF(reports, metadata)
+	case MARKDOWN:
+		return RenderMarkdown(reports, metadata)
+	case HTML:
+		return RenderHTML(reports, metadata)
+	default:
+		return nil, fmt.Errorf("unknown output format: %s", format)
+	}
+}
+
+// Reports normalizes the content stored in the output signal into a list of URL reports.
+func Reports(content any) []webassess.UrlReport {
+	switch c := content.(type) {
+	case webassess.UrlReport:
+		return []webassess.UrlReport{c}
+	case *webassess.UrlReport:
+		if c == nil {
+			return nil
+		}
+		return []webassess.UrlReport{*c}
+	case []webassess.UrlReport:
+		return c
+	default:
+		return nil
+	}
+}
+
+func writeToFileOrStdout(data []byte, filePath *string) error {
+	if filePath == nil {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(*filePath, data, 0644)
+}
+
+// RunMetrics aggregates the usage metrics of the reports, or returns nil if none of them recorded metrics.
+func RunMetrics(reports []webassess.UrlReport) *webassess.UsageMetrics {
+	metrics := make([]*webassess.UsageMetrics, 0, len(reports))
+	for _, report := range reports {
+		metrics = append(metrics, report.Metrics)
+	}
+	return url.MergeUsageMetrics(metrics...)
+}
@@ -0,0 +1,307 @@
+package report
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/url"
+)
+
+const (
+	sarifSchema            = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion           = "2.1.0"
+	sarifInformationURI    = "https://github.com/Method-Security/webassess"
+	sarifFingerprintFormat = "webassess/v1"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool        sarifTool         `json:"tool"`
+	Invocations []sarifInvocation `json:"invocations"`
+	Results     []sarifResult     `json:"results"`
+	Properties  map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version,omitempty"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string            `json:"id"`
+	Name                 string            `json:"name"`
+	ShortDescription     sarifMessage      `json:"shortDescription"`
+	FullDescription      sarifMessage      `json:"fullDescription"`
+	DefaultConfiguration sarifRuleDefaults `json:"defaultConfiguration"`
+	Properties           map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifRuleDefaults struct {
+	Level string `json:"level"`
+}
+
+type sarifInvocation struct {
+	ExecutionSuccessful        bool                `json:"executionSuccessful"`
+	StartTimeUTC               string              `json:"startTimeUtc,omitempty"`
+	EndTimeUTC                 string              `json:"endTimeUtc,omitempty"`
+	ToolExecutionNotifications []sarifNotification `json:"toolExecutionNotifications,omitempty"`
+}
+
+type sarifNotification struct {
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             sarifMessage      `json:"message"`
+	Locations           []sarifLocation   `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+	Properties          map[string]any    `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int           `json:"startLine,omitempty"`
+	StartColumn int           `json:"startColumn,omitempty"`
+	Snippet     *sarifMessage `json:"snippet,omitempty"`
+}
+
+type ruleDefinition struct {
+	category    webassess.FindingCategory
+	name        string
+	short       string
+	description string
+}
+
+// ruleDefinitions lists the SARIF rules emitted by webassess, one for each finding category.
+var ruleDefinitions = []ruleDefinition{
+	{
+		category:    webassess.FindingCategoryVulnerability,
+		name:        "PotentialVulnerability",
+		short:       "Potential vulnerability in web content",
+		description: "The model identified a potential vulnerability in the content served by the target.",
+	},
+	{
+		category:    webassess.FindingCategorySensitiveData,
+		name:        "SensitiveDataExposure",
+		short:       "Sensitive data exposed in web content",
+		description: "The model identified potentially sensitive data exposed in the content served by the target.",
+	},
+	{
+		category:    webassess.FindingCategoryPromptInjection,
+		name:        "PromptInjection",
+		short:       "Instructions aimed at AI models in web content",
+		description: "The content served by the target contains text, often hidden from visitors, that tries to instruct the AI agents, assistants and crawlers that read it.",
+	},
+}
+
+// RenderSARIF renders the reports as a SARIF 2.1.0 log containing a single run. Every finding becomes a result whose
+// rule is derived from the finding category, whose level is derived from the finding severity and whose location is
+// the target URL along with the line and column of the evidence where it was located.
+func RenderSARIF(reports []webassess.UrlReport, metadata Metadata) ([]byte, error) {
+	rules := make([]sarifRule, 0, len(ruleDefinitions))
+	ruleIndex := map[webassess.FindingCategory]int{}
+	for i, definition := range ruleDefinitions {
+		rules = append(rules, sarifRule{
+			ID:                   sarifRuleID(definition.category),
+			Name:                 definition.name,
+			ShortDescription:     sarifMessage{Text: definition.short},
+			FullDescription:      sarifMessage{Text: definition.description},
+			DefaultConfiguration: sarifRuleDefaults{Level: "warning"},
+			Properties:           map[string]any{"tags": []string{"security"}},
+		})
+		ruleIndex[definition.category] = i
+	}
+
+	invocation := sarifInvocation{
+		ExecutionSuccessful: metadata.Status == 0,
+		StartTimeUTC:        time.Time(metadata.StartedAt).UTC().Format(time.RFC3339),
+	}
+	if metadata.CompletedAt != nil {
+		invocation.EndTimeUTC = time.Time(*metadata.CompletedAt).UTC().Format(time.RFC3339)
+	}
+	if metadata.ErrorMessage != nil {
+		invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+			Level:   "error",
+			Message: sarifMessage{Text: *metadata.ErrorMessage},
+		})
+	}
+
+	results := []sarifResult{}
+	highest := map[webassess.FindingCategory]webassess.Severity{}
+	for _, report := range reports {
+		for _, reportError := range report.Errors {
+			invocation.ToolExecutionNotifications = append(invocation.ToolExecutionNotifications, sarifNotification{
+				Level:     "error",
+				Message:   sarifMessage{Text: reportError},
+				Locations: []sarifLocation{{PhysicalLocation: sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: report.Target}}}},
+			})
+		}
+		if report.Status != nil && *report.Status != webassess.ReportStatusCompleted {
+			invocation.ExecutionSuccessful = false
+		}
+		if report.Assessment == nil {
+			continue
+		}
+		for _, finding := range report.Assessment.Findings {
+			results = append(results, sarifResultForFinding(report.Target, finding, ruleIndex[finding.Category]))
+			if current, ok := highest[finding.Category]; !ok || url.SeverityRank(finding.Severity) > url.SeverityRank(current) {
+				highest[finding.Category] = finding.Severity
+			}
+		}
+	}
+
+	// GitHub code scanning ranks results by the security severity of their rule, so every rule carries the highest
+	// severity of its findings
+	for category, severity := range highest {
+		rules[ruleIndex[category]].Properties["security-severity"] = sarifSecuritySeverity(severity)
+	}
+
+	properties := map[string]any{
+		"model":         metadata.Model.Name,
+		"modelSize":     metadata.Model.Size,
+		"contextWindow": metadata.Model.ContextWindowSize,
+	}
+	if metadata.Metrics != nil {
+		properties["metrics"] = metadata.Metrics
+	}
+
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "webassess",
+						Version:        metadata.ToolVersion,
+						InformationURI: sarifInformationURI,
+						Rules:          rules,
+					},
+				},
+				Invocations: []sarifInvocation{invocation},
+				Results:     results,
+				Properties:  properties,
+			},
+		},
+	}
+
+	return json.MarshalIndent(log, "", "  ")
+}
+
+func sarifResultForFinding(target string, finding *webassess.Finding, ruleIndex int) sarifResult {
+	location := sarifPhysicalLocation{ArtifactLocation: sarifArtifactLocation{URI: target}}
+	// A region must carry a start line to be valid, so findings whose evidence was not located only reference the target
+	if finding.Line != nil {
+		region := &sarifRegion{StartLine: *finding.Line}
+		if finding.Column != nil {
+			region.StartColumn = *finding.Column
+		}
+		if finding.Evidence != nil {
+			region.Snippet = &sarifMessage{Text: *finding.Evidence}
+		}
+		location.Region = region
+	}
+
+	message := finding.Title
+	if finding.Description != "" {
+		message = finding.Title + ": " + finding.Description
+	}
+
+	properties := map[string]any{
+		"severity": string(finding.Severity),
+	}
+	if finding.Evidence != nil && finding.Line == nil {
+		properties["evidence"] = *finding.Evidence
+	}
+	if finding.Verification != nil {
+		properties["verification"] = string(finding.Verification.Status)
+		properties["verificationMethod"] = string(finding.Verification.Method)
+	}
+	if finding.Model != nil {
+		properties["model"] = *finding.Model
+	}
+	if finding.Consensus != nil {
+		properties["models"] = finding.Consensus.Models
+		properties["agreement"] = finding.Consensus.Agreement
+		properties["ensembleSize"] = finding.Consensus.EnsembleSize
+		if finding.Consensus.JudgeVerdict != nil {
+			properties["judgeVerdict"] = string(*finding.Consensus.JudgeVerdict)
+		}
+	}
+
+	return sarifResult{
+		RuleID:              sarifRuleID(finding.Category),
+		RuleIndex:           ruleIndex,
+		Level:               sarifLevel(finding.Severity),
+		Message:             sarifMessage{Text: message},
+		Locations:           []sarifLocation{{PhysicalLocation: location}},
+		PartialFingerprints: map[string]string{sarifFingerprintFormat: finding.Fingerprint},
+		Properties:          properties,
+	}
+}
+
+func sarifRuleID(category webassess.FindingCategory) string {
+	return "webassess/" + strings.ReplaceAll(strings.ToLower(string(category)), "_", "-")
+}
+
+func sarifLevel(severity webassess.Severity) string {
+	switch severity {
+	case webassess.SeverityCritical, webassess.SeverityHigh:
+		return "error"
+	case webassess.SeverityMedium:
+		return "warning"
+	default:
+		return "note"
+	}
+}
+
+// sarifSecuritySeverity maps severities onto the numeric scale GitHub code scanning reads from the properties of rules
+// to rank security results.
+func sarifSecuritySeverity(severity webassess.Severity) string {
+	switch severity {
+	case webassess.SeverityCritical:
+		return "9.5"
+	case webassess.SeverityHigh:
+		return "8.0"
+	case webassess.SeverityMedium:
+		return "5.5"
+	case webassess.SeverityLow:
+		return "2.0"
+	default:
+		return "0.0"
+	}
+}
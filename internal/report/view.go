@@ -0,0 +1,218 @@
+package report
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/url"
+)
+
+// severities lists the finding severities from most to least severe.
+var severities = []webassess.Severity{
+	webassess.SeverityCritical,
+	webassess.SeverityHigh,
+	webassess.SeverityMedium,
+	webassess.SeverityLow,
+	webassess.SeverityInfo,
+}
+
+// reportView is the data model shared by the human readable report templates.
+type reportView struct {
+	ToolVersion string
+	Model       string
+	ModelSize   string
+	Context     int
+	StartedAt   string
+	CompletedAt string
+	Status      int
+	Error       string
+	Metrics     *webassess.UsageMetrics
+	Totals      []severityCount
+	Targets     []targetView
+}
+
+// targetView describes the report of a single target. Incomplete explains why the assessment did not complete, and
+// is empty when it did. Ensemble lists the models that assessed the target, and is empty for a single model. Cascade
+// describes the screening of the target when it was assessed by a cascade of models.
+type targetView struct {
+	Target     string
+	Incomplete string
+	Ensemble   string
+	Cascade    string
+	Fetch      *webassess.FetchMetadata
+	Assessment *webassess.UrlAssessment
+	Findings   []*webassess.Finding
+	Discarded  []*webassess.Finding
+	Diff       *webassess.TargetDiff
+	Errors     []string
+	Counts     []severityCount
+}
+
+type severityCount struct {
+	Severity webassess.Severity
+	Count    int
+}
+
+func newReportView(reports []webassess.UrlReport, metadata Metadata) reportView {
+	view := reportView{
+		ToolVersion: metadata.ToolVersion,
+		Model:       metadata.Model.Name,
+		ModelSize:   formatBytes(metadata.Model.Size),
+		Context:     metadata.Model.ContextWindowSize,
+		StartedAt:   time.Time(metadata.StartedAt).UTC().Format(time.RFC3339),
+		Status:      metadata.Status,
+		Metrics:     metadata.Metrics,
+	}
+	if metadata.CompletedAt != nil {
+		view.CompletedAt = time.Time(*metadata.CompletedAt).UTC().Format(time.RFC3339)
+	}
+	if metadata.ErrorMessage != nil {
+		view.Error = *metadata.ErrorMessage
+	}
+
+	totals := map[webassess.Severity]int{}
+	for _, report := range reports {
+		target := targetView{
+			Target:     report.Target,
+			Fetch:      report.Fetch,
+			Assessment: report.Assessment,
+			Diff:       report.Diff,
+			Errors:     report.Errors,
+			Incomplete: incompleteReason(report.Status),
+			Ensemble:   ensembleLabel(report.Ensemble),
+			Cascade:    cascadeLabel(report.Cascade),
+		}
+		counts := map[webassess.Severity]int{}
+		if report.Assessment != nil {
+			target.Findings = sortedFindings(report.Assessment.Findings)
+			target.Discarded = sortedFindings(report.Assessment.DiscardedFindings)
+			for _, finding := range target.Findings {
+				counts[finding.Severity]++
+				totals[finding.Severity]++
+			}
+		}
+		target.Counts = severityCounts(counts)
+		view.Targets = append(view.Targets, target)
+	}
+	view.Totals = severityCounts(totals)
+
+	return view
+}
+
+// sortedFindings returns a copy of the findings ordered from most to least severe, keeping the model's order for
+// findings of equal severity.
+func sortedFindings(findings []*webassess.Finding) []*webassess.Finding {
+	sorted := make([]*webassess.Finding, len(findings))
+	copy(sorted, findings)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return url.SeverityRank(sorted[i].Severity) > url.SeverityRank(sorted[j].Severity)
+	})
+	return sorted
+}
+
+func severityCounts(counts map[webassess.Severity]int) []severityCount {
+	result := make([]severityCount, 0, len(severities))
+	for _, severity := range severities {
+		result = append(result, severityCount{Severity: severity, Count: counts[severity]})
+	}
+	return result
+}
+
+func formatBytes(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+func durationLabel(milliseconds int64) string {
+	return (time.Duration(milliseconds) * time.Millisecond).String()
+}
+
+func verificationLabel(finding *webassess.Finding) string {
+	if finding.Verification == nil {
+		return "-"
+	}
+	return strings.ToLower(string(finding.Verification.Status))
+}
+
+// ensembleLabel lists the models of the ensemble, followed by the judge model if there was one.
+func ensembleLabel(ensemble *webassess.EnsembleSummary) string {
+	if ensemble == nil {
+		return ""
+	}
+	models := make([]string, 0, len(ensemble.Members))
+	for _, member := range ensemble.Members {
+		models = append(models, member.Model)
+	}
+	label := strings.Join(models, ", ")
+	if ensemble.JudgeModel != nil {
+		label += fmt.Sprintf(" (judged by %s)", *ensemble.JudgeModel)
+	}
+	return label
+}
+
+// cascadeLabel describes how many chunks the screening model escalated to the larger model.
+func cascadeLabel(cascade *webassess.CascadeSummary) string {
+	if cascade == nil {
+		return ""
+	}
+	return fmt.Sprintf("%d of %d chunks screened by %s were analyzed by %s", cascade.EscalatedChunks, len(cascade.Chunks), cascade.ScreenModel, cascade.EscalationModel)
+}
+
+// agreementLabel shows how many models of the ensemble reported the finding, along with the verdict of the judge model.
+func agreementLabel(finding *webassess.Finding) string {
+	if finding.Consensus == nil {
+		return "-"
+	}
+	label := fmt.Sprintf("%d/%d", finding.Consensus.Agreement, finding.Consensus.EnsembleSize)
+	if finding.Consensus.JudgeVerdict != nil {
+		label += ", judge " + strings.ToLower(string(*finding.Consensus.JudgeVerdict))
+	}
+	return label
+}
+
+func locationLabel(finding *webassess.Finding) string {
+	if finding.Line == nil {
+		return "-"
+	}
+	if finding.Column == nil {
+		return fmt.Sprintf("%d", *finding.Line)
+	}
+	return fmt.Sprintf("%d:%d", *finding.Line, *finding.Column)
+}
+
+func categoryLabel(category webassess.FindingCategory) string {
+	return strings.ReplaceAll(strings.ToLower(string(category)), "_", " ")
+}
+
+func deref(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+// incompleteReason describes why an assessment with the status did not complete, or returns an empty string if it did.
+func incompleteReason(status *webassess.ReportStatus) string {
+	if status == nil {
+		return ""
+	}
+	switch *status {
+	case webassess.ReportStatusCancelled:
+		return "The assessment was cancelled before it completed. The findings cover the content analyzed until then."
+	case webassess.ReportStatusTimedOut:
+		return "The assessment timed out before it completed. The findings cover the content analyzed until then."
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,184 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/report"
+	"github.com/palantir/pkg/datetime"
+)
+
+// Handler returns the HTTP handler serving the API:
+//
+//	POST   /v1/assessments              submit an AssessmentRequest, returns the queued AssessmentJob
+//	GET    /v1/assessments              list the jobs as an AssessmentJobList, without their reports
+//	GET    /v1/assessments/{id}         get an AssessmentJob, including its UrlReport once finished
+//	GET    /v1/assessments/{id}/report  get the UrlReport, in the format given by the format query parameter
+//	DELETE /v1/assessments/{id}         cancel a queued or running job
+//	GET    /healthz                     get the ServerHealth
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/assessments", s.handleSubmit)
+	mux.HandleFunc("GET /v1/assessments", s.handleList)
+	mux.HandleFunc("GET /v1/assessments/{id}", s.handleGet)
+	mux.HandleFunc("GET /v1/assessments/{id}/report", s.handleReport)
+	mux.HandleFunc("DELETE /v1/assessments/{id}", s.handleCancel)
+	mux.HandleFunc("GET /healthz", s.handleHealth)
+	return mux
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	var request webassess.AssessmentRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, s.options.MaxRequestBytes)).Decode(&request); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request: %v", err))
+		return
+	}
+	if err := validateRequest(&request); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	job, err := s.Submit(&request)
+	switch {
+	case errors.Is(err, ErrQueueFull), errors.Is(err, ErrShuttingDown):
+		w.Header().Set("Retry-After", retryAfterSeconds)
+		writeError(w, http.StatusServiceUnavailable, err)
+		return
+	case err != nil:
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Location", "/v1/assessments/"+job.Id)
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleList(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, &webassess.AssessmentJobList{Jobs: s.Jobs()})
+}
+
+func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	writeJSON(w, http.StatusOK, job)
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	job, ok := s.Job(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	if job.Report == nil {
+		writeError(w, http.StatusConflict, fmt.Errorf("job %s has no report, its status is %s", job.Id, job.Status))
+		return
+	}
+
+	format := strings.ToLower(r.URL.Query().Get("format"))
+	if format == "" || format == "json" {
+		writeJSON(w, http.StatusOK, job.Report)
+		return
+	}
+	if !report.IsFormat(format) {
+		writeError(w, http.StatusBadRequest, errors.New("invalid format. Valid formats are: json, sarif, markdown, html"))
+		return
+	}
+
+	metadata := report.Metadata{
+		ToolVersion: s.options.ToolVersion,
+		Model:       s.model,
+		StartedAt:   datetime.DateTime(job.SubmittedAt),
+	}
+	if job.StartedAt != nil {
+		metadata.StartedAt = datetime.DateTime(*job.StartedAt)
+	}
+	if job.CompletedAt != nil {
+		completedAt := datetime.DateTime(*job.CompletedAt)
+		metadata.CompletedAt = &completedAt
+	}
+	if job.Status != webassess.JobStatusCompleted {
+		metadata.Status = 1
+		metadata.ErrorMessage = job.Error
+	}
+	data, err := report.Render(*job.Report, report.Format(format), metadata)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentTypes[report.Format(format)])
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(data)
+}
+
+func (s *Server) handleCancel(w http.ResponseWriter, r *http.Request) {
+	job, ok, err := s.Cancel(r.PathValue("id"))
+	if !ok {
+		writeError(w, http.StatusNotFound, errors.New("job not found"))
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusConflict, err)
+		return
+	}
+	writeJSON(w, http.StatusAccepted, job)
+}
+
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	health := s.Health()
+	status := http.StatusOK
+	if health.Status != "ok" {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, health)
+}
+
+// retryAfterSeconds is the delay clients are asked to wait before resubmitting a job that was rejected.
+const retryAfterSeconds = "30"
+
+var contentTypes = map[report.Format]string{
+	report.SARIF:    "application/sarif+json",
+	report.MARKDOWN: "text/markdown; charset=utf-8",
+	report.HTML:     "text/html; charset=utf-8",
+}
+
+// validateRequest checks that the target of a request can be assessed. Requests with content are not fetched, so
+// their target is only used as a label.
+func validateRequest(request *webassess.AssessmentRequest) error {
+	request.Target = strings.TrimSpace(request.Target)
+	if request.Target == "" {
+		return errors.New("target is required")
+	}
+	if request.Content != nil {
+		return nil
+	}
+	target, err := neturl.Parse(request.Target)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return errors.New("target must be an http or https URL unless content is provided")
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	data, _ := json.Marshal(map[string]string{"error": err.Error()})
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_, _ = w.Write(data)
+}
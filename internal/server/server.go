@@ -0,0 +1,364 @@
+// Package server exposes assessments over HTTP. Assessments are submitted as jobs to a bounded queue and processed by
+// a fixed number of workers against a model that is kept loaded, so that clients do not pay the start up cost of the
+// CLI for every assessment.
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/url"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// Options controls the HTTP server and its job queue. Finished jobs are forgotten once they are older than
+// JobRetention, unless it is zero. On shutdown, running jobs are given ShutdownTimeout to complete before they are
+// cancelled.
+type Options struct {
+	Address         string
+	Concurrency     int
+	QueueSize       int
+	JobRetention    time.Duration
+	ShutdownTimeout time.Duration
+	MaxRequestBytes int64
+	ToolVersion     string
+}
+
+// ErrQueueFull is returned when a job is submitted while the queue is at capacity.
+var ErrQueueFull = errors.New("the job queue is full")
+
+// ErrShuttingDown is returned when a job is submitted while the server is shutting down.
+var ErrShuttingDown = errors.New("the server is shutting down")
+
+// Server runs assessment jobs submitted over HTTP.
+type Server struct {
+	model      ollama.Model
+	assessment url.Options
+	options    Options
+
+	mu      sync.Mutex
+	jobs    map[string]*job
+	queue   chan *job
+	closing bool
+	// queued counts the jobs waiting to start, which excludes the jobs cancelled while still in the queue
+	queued  int
+	running int
+}
+
+type job struct {
+	state             *webassess.AssessmentJob
+	content           *string
+	modelVerification *bool
+	cancel            context.CancelFunc
+}
+
+// New creates a server that assesses targets with the model and the assessment options.
+func New(model ollama.Model, assessment url.Options, options Options) *Server {
+	if options.Concurrency < 1 {
+		options.Concurrency = 1
+	}
+	if options.QueueSize < 1 {
+		options.QueueSize = 1
+	}
+	if options.MaxRequestBytes <= 0 {
+		options.MaxRequestBytes = 10 << 20
+	}
+	return &Server{
+		model:      model,
+		assessment: assessment,
+		options:    options,
+		jobs:       map[string]*job{},
+		queue:      make(chan *job, options.QueueSize),
+	}
+}
+
+// Run serves the API until the context is cancelled, then shuts down gracefully: the listener is closed, queued jobs
+// are cancelled and running jobs are given the shutdown timeout to complete before they are cancelled as well.
+func (s *Server) Run(ctx context.Context) error {
+	log := svc1log.FromContext(ctx)
+
+	listener, err := net.Listen("tcp", s.options.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", s.options.Address, err)
+	}
+
+	jobCtx, cancelJobs := context.WithCancel(svc1log.WithLogger(context.Background(), log))
+	defer cancelJobs()
+
+	var workers sync.WaitGroup
+	for i := 0; i < s.options.Concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for j := range s.queue {
+				s.runJob(jobCtx, j)
+			}
+		}()
+	}
+
+	janitorCtx, stopJanitor := context.WithCancel(ctx)
+	defer stopJanitor()
+	go s.janitor(janitorCtx)
+
+	httpServer := &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	serveErr := make(chan error, 1)
+	go func() {
+		serveErr <- httpServer.Serve(listener)
+	}()
+	log.Info("Serving assessments", svc1log.SafeParam("address", listener.Addr().String()), svc1log.SafeParam("model", s.model.Name))
+
+	select {
+	case <-ctx.Done():
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			cancelJobs()
+			s.close()
+			workers.Wait()
+			return fmt.Errorf("server failed: %v", err)
+		}
+	}
+
+	log.Info("Shutting down", svc1log.SafeParam("timeout", s.options.ShutdownTimeout.String()))
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), s.options.ShutdownTimeout)
+	defer cancelShutdown()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		log.Warn("Failed to close open connections", svc1log.Stacktrace(err))
+	}
+
+	s.close()
+	done := make(chan struct{})
+	go func() {
+		workers.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-shutdownCtx.Done():
+		log.Warn("Cancelling running jobs that did not complete within the shutdown timeout")
+		cancelJobs()
+		<-done
+	}
+	return nil
+}
+
+// Submit queues a job for the request.
+func (s *Server) Submit(request *webassess.AssessmentRequest) (*webassess.AssessmentJob, error) {
+	id, err := newJobID()
+	if err != nil {
+		return nil, err
+	}
+	j := &job{
+		state: &webassess.AssessmentJob{
+			Id:          id,
+			Status:      webassess.JobStatusQueued,
+			Target:      request.Target,
+			SubmittedAt: time.Now().UTC(),
+		},
+		content:           request.Content,
+		modelVerification: request.ModelVerification,
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return nil, ErrShuttingDown
+	}
+	select {
+	case s.queue <- j:
+	default:
+		return nil, ErrQueueFull
+	}
+	s.jobs[id] = j
+	s.queued++
+	return s.snapshot(j), nil
+}
+
+// Job returns the job with the given ID.
+func (s *Server) Job(id string) (*webassess.AssessmentJob, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, false
+	}
+	return s.snapshot(j), true
+}
+
+// Jobs returns every job the server knows about in the order they were submitted, without their reports.
+func (s *Server) Jobs() []*webassess.AssessmentJob {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]*webassess.AssessmentJob, 0, len(s.jobs))
+	for _, j := range s.jobs {
+		snapshot := s.snapshot(j)
+		snapshot.Report = nil
+		jobs = append(jobs, snapshot)
+	}
+	sort.Slice(jobs, func(i, k int) bool { return jobs[i].SubmittedAt.Before(jobs[k].SubmittedAt) })
+	return jobs
+}
+
+// Cancel cancels a queued or running job. It returns false if the job does not exist, and an error if it has already
+// finished.
+func (s *Server) Cancel(id string) (*webassess.AssessmentJob, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j, ok := s.jobs[id]
+	if !ok {
+		return nil, false, nil
+	}
+	switch j.state.Status {
+	case webassess.JobStatusQueued:
+		s.queued--
+		s.finish(j, webassess.JobStatusCancelled, nil, "cancelled")
+	case webassess.JobStatusRunning:
+		// The worker records the cancellation once the assessment returns
+		j.cancel()
+	default:
+		return s.snapshot(j), true, fmt.Errorf("job %s has already finished", id)
+	}
+	return s.snapshot(j), true, nil
+}
+
+// Health summarizes the state of the server.
+func (s *Server) Health() *webassess.ServerHealth {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	status := "ok"
+	if s.closing {
+		status = "shutting down"
+	}
+	return &webassess.ServerHealth{
+		Status:      status,
+		Model:       s.model.Name,
+		Concurrency: s.options.Concurrency,
+		Queued:      s.queued,
+		Running:     s.running,
+	}
+}
+
+func (s *Server) runJob(ctx context.Context, j *job) {
+	s.mu.Lock()
+	if j.state.Status != webassess.JobStatusQueued {
+		s.mu.Unlock()
+		return
+	}
+	s.queued--
+	if s.closing {
+		s.finish(j, webassess.JobStatusCancelled, nil, "the server shut down before the job started")
+		s.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	startedAt := time.Now().UTC()
+	j.state.Status = webassess.JobStatusRunning
+	j.state.StartedAt = &startedAt
+	j.cancel = cancel
+	s.running++
+	s.mu.Unlock()
+
+	options := s.assessment
+	if j.modelVerification != nil {
+		options.ModelVerification = *j.modelVerification
+	}
+	var report webassess.UrlReport
+	if j.content != nil {
+		report = url.PerformContentAssess(ctx, j.state.Target, *j.content, s.model, options)
+	} else {
+		report = url.PerformURLAssess(ctx, j.state.Target, s.model, options)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running--
+	switch {
+	case ctx.Err() != nil:
+		s.finish(j, webassess.JobStatusCancelled, &report, "cancelled")
+	case !url.ModelAssessed(report.Assessment) && len(report.Errors) > 0:
+		s.finish(j, webassess.JobStatusFailed, &report, report.Errors[0])
+	default:
+		s.finish(j, webassess.JobStatusCompleted, &report, "")
+	}
+}
+
+// finish records the outcome of a job. The caller must hold the lock.
+func (s *Server) finish(j *job, status webassess.JobStatus, report *webassess.UrlReport, message string) {
+	completedAt := time.Now().UTC()
+	j.state.Status = status
+	j.state.CompletedAt = &completedAt
+	j.state.Report = report
+	if message != "" {
+		j.state.Error = &message
+	}
+}
+
+// snapshot copies the state of a job so that it can be used without holding the lock. The caller must hold the lock.
+func (s *Server) snapshot(j *job) *webassess.AssessmentJob {
+	return &webassess.AssessmentJob{
+		Id:          j.state.Id,
+		Status:      j.state.Status,
+		Target:      j.state.Target,
+		SubmittedAt: j.state.SubmittedAt,
+		StartedAt:   j.state.StartedAt,
+		CompletedAt: j.state.CompletedAt,
+		Report:      j.state.Report,
+		Error:       j.state.Error,
+	}
+}
+
+// close stops accepting jobs and lets the workers exit once the queue is drained.
+func (s *Server) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closing {
+		return
+	}
+	s.closing = true
+	close(s.queue)
+}
+
+// janitor periodically forgets finished jobs that are older than the retention period.
+func (s *Server) janitor(ctx context.Context) {
+	if s.options.JobRetention <= 0 {
+		return
+	}
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.mu.Lock()
+			for id, j := range s.jobs {
+				if j.state.CompletedAt != nil && now.Sub(*j.state.CompletedAt) > s.options.JobRetention {
+					delete(s.jobs, id)
+				}
+			}
+			s.mu.Unlock()
+		}
+	}
+}
+
+func newJobID() (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", fmt.Errorf("failed to generate job ID: %v", err)
+	}
+	return hex.EncodeToString(id), nil
+}
@@ -0,0 +1,178 @@
+package url
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/progress"
+	"github.com/ollama/ollama/api"
+)
+
+type screeningAnswer struct {
+	Suspicious bool   `json:"suspicious"`
+	Reason     string `json:"reason"`
+}
+
+// assessCascade screens every chunk of the content with the assessment model and the pattern scanners, and only
+// analyzes the chunks that either of them flags with the larger cascade model, which is too slow to run on everything.
+// The findings are those of the cascade model, with their location in the full content.
+func assessCascade(ctx context.Context, client *api.Client, report *webassess.UrlReport, start time.Time, content string, model ollama.Model, options Options) {
+	summary := &webassess.CascadeSummary{
+		ScreenModel:     model.Name,
+		EscalationModel: options.Cascade.Name,
+		Chunks:          []*webassess.ScreenedChunk{},
+	}
+	report.Cascade = summary
+
+	screen := newGenerator(client, model, options)
+	screenStart := time.Now()
+	escalated, err := screenChunks(ctx, screen, report.Target, ollama.SplitContent(content, options.Chunking.Size), summary, withModel(options.Progress, model.Name))
+	summary.ScreenMetrics = NewUsageMetrics(screen.Usage(), time.Since(screenStart))
+	summary.EscalatedChunks = len(escalated)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		report.Metrics = cascadeMetrics(summary, start)
+		return
+	}
+	if len(escalated) == 0 {
+		report.Assessment = &webassess.UrlAssessment{
+			CodeSummary: "The screening model and the pattern scanners did not flag any content for a detailed analysis.",
+			Findings:    []*webassess.Finding{},
+		}
+		report.Metrics = cascadeMetrics(summary, start)
+		return
+	}
+
+	escalation := newGenerator(client, *options.Cascade, options)
+	escalationOptions := options
+	escalationOptions.Progress = withModel(options.Progress, options.Cascade.Name)
+	escalationStart := time.Now()
+	output, assessment, err := AssessContent(ctx, escalation, report.Target, strings.Join(escalated, "\n"), escalationOptions)
+	summary.EscalationMetrics = NewUsageMetrics(escalation.Usage(), time.Since(escalationStart))
+	report.Output = output
+	if assessment != nil {
+		relocateFindings(content, assessment)
+	}
+	report.Assessment = assessment
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+	}
+	report.Metrics = cascadeMetrics(summary, start)
+}
+
+// screenChunks screens every chunk and returns those to escalate, recording the outcome for each chunk in the summary.
+// A chunk is escalated if the screening model finds it suspicious, if a scanner or an injection scanner matches it, or
+// if it could not be screened, so that a failure of the screening model does not hide anything. Only the cancellation
+// of the context stops the screening.
+func screenChunks(ctx context.Context, screen *ollama.Generator, target string, chunks []string, summary *webassess.CascadeSummary, reporter progress.Reporter) ([]string, error) {
+	task := progress.Start(reporter, target, progress.UnitChunks)
+	task.SetStatus("screening")
+	task.AddTotal(int64(len(chunks)))
+
+	escalated := []string{}
+	for i, chunk := range chunks {
+		screened := &webassess.ScreenedChunk{Index: i, ContentBytes: int64(len(chunk)), Reasons: []string{}}
+		for _, name := range scanChunk(chunk) {
+			screened.Reasons = append(screened.Reasons, "scanner: "+name)
+		}
+		// The screening model may follow instructions embedded in the chunk, such as to report nothing
+		for _, s := range injectionScanners {
+			if s.pattern.MatchString(chunk) {
+				screened.Reasons = append(screened.Reasons, "injection: "+s.name)
+			}
+		}
+
+		answer, err := screenChunk(ctx, screen, chunk)
+		if ctx.Err() != nil {
+			err = fmt.Errorf("screening interrupted after %d of %d chunks: %v", i, len(chunks), err)
+			task.Done(err)
+			return nil, err
+		}
+		switch {
+		case err != nil:
+			screened.Reasons = append(screened.Reasons, fmt.Sprintf("screening failed: %v", err))
+		case answer.Suspicious:
+			screened.Reasons = append(screened.Reasons, "screening model: "+answer.Reason)
+		}
+
+		screened.Escalated = len(screened.Reasons) > 0
+		if screened.Escalated {
+			escalated = append(escalated, chunk)
+		}
+		summary.Chunks = append(summary.Chunks, screened)
+		task.Advance(1)
+	}
+	task.Done(nil)
+	return escalated, nil
+}
+
+func screenChunk(ctx context.Context, screen *ollama.Generator, chunk string) (*screeningAnswer, error) {
+	output, err := screen.ProcessContentRecursivelyMerged(ctx, chunk, CreateScreeningPrompt, mergeScreeningAnswers)
+	if err != nil {
+		return nil, err
+	}
+	return parseScreeningAnswer(output)
+}
+
+func parseScreeningAnswer(output string) (*screeningAnswer, error) {
+	raw, err := extractJSONObject(output)
+	if err != nil {
+		return nil, err
+	}
+	var answer screeningAnswer
+	if err := json.Unmarshal([]byte(raw), &answer); err != nil {
+		return nil, fmt.Errorf("failed to parse screening output: %v", err)
+	}
+	return &answer, nil
+}
+
+// mergeScreeningAnswers combines the screening answers of the two halves of a chunk that exceeded the context length
+// of the screening model. The screening model may be steered by the chunk, so the answers are combined in code rather
+// than by the model: the chunk is suspicious if either half is, keeping the reason of the suspicious half, and a half
+// whose answer cannot be parsed makes the chunk suspicious as well.
+func mergeScreeningAnswers(firstOutput string, secondOutput string) string {
+	merged := screeningAnswer{}
+	for _, output := range []string{firstOutput, secondOutput} {
+		answer, err := parseScreeningAnswer(output)
+		switch {
+		case err != nil:
+			answer = &screeningAnswer{Suspicious: true, Reason: fmt.Sprintf("a part of the chunk could not be screened: %v", err)}
+		case merged.Reason == "":
+			merged.Reason = answer.Reason
+		}
+		if answer.Suspicious && !merged.Suspicious {
+			merged = *answer
+		}
+	}
+	data, _ := json.Marshal(merged)
+	return string(data)
+}
+
+// relocateFindings records the line and column of the located findings in the full content, since the escalated
+// chunks were analyzed without the chunks around them.
+func relocateFindings(content string, assessment *webassess.UrlAssessment) {
+	for _, finding := range assessment.Findings {
+		if finding.Line == nil || finding.Evidence == nil {
+			continue
+		}
+		if line, column, ok := locateEvidence(content, *finding.Evidence); ok {
+			finding.Line = &line
+			finding.Column = &column
+		}
+	}
+}
+
+// cascadeMetrics aggregates the metrics of the screening and the escalation, with the wall time of the whole
+// assessment of the target.
+func cascadeMetrics(summary *webassess.CascadeSummary, start time.Time) *webassess.UsageMetrics {
+	metrics := MergeUsageMetrics(summary.ScreenMetrics, summary.EscalationMetrics)
+	if metrics != nil {
+		metrics.WallTimeMs = time.Since(start).Milliseconds()
+	}
+	return metrics
+}
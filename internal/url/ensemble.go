@@ -0,0 +1,243 @@
+package url
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/progress"
+	"github.com/ollama/ollama/api"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// EnsembleOptions runs the same analysis with several models and merges their findings. When Judge is set, findings
+// that not every model reported are adjudicated by the judge model, which is usually larger than the ensemble models.
+type EnsembleOptions struct {
+	Models []ollama.Model
+	Judge  *ollama.Model
+}
+
+// memberResult is the outcome of the analysis of the content by a single model of the ensemble.
+type memberResult struct {
+	model      ollama.Model
+	output     string
+	assessment *webassess.UrlAssessment
+	usage      ollama.Usage
+	duration   time.Duration
+	err        error
+}
+
+// assessEnsemble analyzes the content with every model of the ensemble in turn, each with the full chunking, synthesis
+// and verification pipeline, then merges their findings by fingerprint. The number of models that reported a finding
+// is recorded as its agreement, which serves as a confidence signal.
+func assessEnsemble(ctx context.Context, client *api.Client, report *webassess.UrlReport, start time.Time, content string, options Options) {
+	results := make([]memberResult, 0, len(options.Ensemble.Models))
+	for _, model := range options.Ensemble.Models {
+		if ctx.Err() != nil {
+			break
+		}
+		generator := newGenerator(client, model, options)
+		memberOptions := options
+		memberOptions.Progress = withModel(options.Progress, model.Name)
+		memberStart := time.Now()
+		output, assessment, err := AssessContent(ctx, generator, report.Target, content, memberOptions)
+		results = append(results, memberResult{model: model, output: output, assessment: assessment, usage: generator.Usage(), duration: time.Since(memberStart), err: err})
+	}
+	// The agreement of the merged findings is counted over the models that ran, so skipped models must not go unnoticed
+	if len(results) < len(options.Ensemble.Models) {
+		report.Errors = append(report.Errors, fmt.Sprintf("%v (ensemble interrupted after %d of %d models)", ctx.Err(), len(results), len(options.Ensemble.Models)))
+	}
+
+	summary := &webassess.EnsembleSummary{Members: []*webassess.EnsembleMember{}}
+	metrics := []*webassess.UsageMetrics{}
+	outputs := []string{}
+	for _, result := range results {
+		member := &webassess.EnsembleMember{
+			Model:   result.model.Name,
+			Metrics: NewUsageMetrics(result.usage, result.duration),
+			Errors:  []string{},
+		}
+		if result.assessment != nil {
+			member.Findings = len(result.assessment.Findings)
+		}
+		if result.err != nil {
+			member.Errors = append(member.Errors, result.err.Error())
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", result.model.Name, result.err))
+		}
+		if result.output != "" {
+			outputs = append(outputs, result.output)
+		}
+		summary.Members = append(summary.Members, member)
+		metrics = append(metrics, member.Metrics)
+	}
+	report.Output = strings.Join(outputs, "\n")
+	report.Ensemble = summary
+
+	report.Assessment = mergeEnsemble(results)
+	if report.Assessment != nil && options.Ensemble.Judge != nil {
+		judge := newGenerator(client, *options.Ensemble.Judge, options)
+		judgeStart := time.Now()
+		adjudicate(ctx, judge, content, report.Assessment)
+		summary.JudgeModel = &options.Ensemble.Judge.Name
+		summary.JudgeMetrics = NewUsageMetrics(judge.Usage(), time.Since(judgeStart))
+		metrics = append(metrics, summary.JudgeMetrics)
+	}
+
+	// The wall time of the report includes fetching the content, which the members do not
+	report.Metrics = MergeUsageMetrics(metrics...)
+	if report.Metrics != nil {
+		report.Metrics.WallTimeMs = time.Since(start).Milliseconds()
+	}
+}
+
+// mergeEnsemble merges the assessments of the ensemble members. Findings with the same fingerprint are merged into the
+// first one reported, unless a later model located its evidence in the content and the first did not. A finding is
+// only discarded if no model kept it. The summaries are taken from the first model that provided them, and nil is
+// returned if no model produced an assessment.
+func mergeEnsemble(results []memberResult) *webassess.UrlAssessment {
+	var merged *webassess.UrlAssessment
+	size := 0
+	byFingerprint := map[string]*webassess.Finding{}
+	models := map[string][]string{}
+	order := []string{}
+	discarded := map[string]*webassess.Finding{}
+	discardedOrder := []string{}
+	for _, result := range results {
+		assessment := result.assessment
+		if assessment == nil {
+			continue
+		}
+		size++
+		if merged == nil {
+			merged = &webassess.UrlAssessment{Findings: []*webassess.Finding{}}
+		}
+		merged.CodeSummary = firstNonEmpty(merged.CodeSummary, assessment.CodeSummary)
+		merged.PotentialVulnerabilities = merged.PotentialVulnerabilities || assessment.PotentialVulnerabilities
+		merged.VulnerabilitiesSummary = firstNonEmpty(merged.VulnerabilitiesSummary, assessment.VulnerabilitiesSummary)
+		merged.PotentialSensitiveData = merged.PotentialSensitiveData || assessment.PotentialSensitiveData
+		merged.SensitiveDataSummary = firstNonEmpty(merged.SensitiveDataSummary, assessment.SensitiveDataSummary)
+
+		for _, finding := range assessment.Findings {
+			existing, ok := byFingerprint[finding.Fingerprint]
+			if !ok {
+				order = append(order, finding.Fingerprint)
+				byFingerprint[finding.Fingerprint] = finding
+			} else if !isVerified(existing) && isVerified(finding) {
+				byFingerprint[finding.Fingerprint] = finding
+			}
+			models[finding.Fingerprint] = appendUnique(models[finding.Fingerprint], result.model.Name)
+		}
+		for _, finding := range assessment.DiscardedFindings {
+			if _, ok := discarded[finding.Fingerprint]; !ok {
+				discardedOrder = append(discardedOrder, finding.Fingerprint)
+				discarded[finding.Fingerprint] = finding
+			}
+		}
+	}
+	if merged == nil {
+		return nil
+	}
+
+	for _, fingerprint := range order {
+		finding := byFingerprint[fingerprint]
+		finding.Consensus = &webassess.FindingConsensus{
+			Models:       models[fingerprint],
+			Agreement:    len(models[fingerprint]),
+			EnsembleSize: size,
+		}
+		merged.Findings = append(merged.Findings, finding)
+	}
+	for _, fingerprint := range discardedOrder {
+		if _, kept := byFingerprint[fingerprint]; !kept {
+			merged.DiscardedFindings = append(merged.DiscardedFindings, discarded[fingerprint])
+		}
+	}
+	return merged
+}
+
+// adjudicate asks the judge model to re-check every finding that not all models of the ensemble reported. Findings the
+// judge rejects are marked REFUTED and moved to the discarded findings, and findings it cannot adjudicate, for instance
+// because the request failed, are kept as they are.
+func adjudicate(ctx context.Context, judge *ollama.Generator, content string, assessment *webassess.UrlAssessment) {
+	log := svc1log.FromContext(ctx)
+
+	kept := []*webassess.Finding{}
+	for _, finding := range assessment.Findings {
+		consensus := finding.Consensus
+		if consensus == nil || consensus.Agreement >= consensus.EnsembleSize {
+			kept = append(kept, finding)
+			continue
+		}
+
+		answer, err := verifyWithModel(ctx, judge, content, finding)
+		if err != nil {
+			log.Warn("Failed to adjudicate finding", svc1log.SafeParam("finding", finding.Title), svc1log.Stacktrace(err))
+			reason := fmt.Sprintf("adjudication failed: %v", err)
+			consensus.JudgeReason = &reason
+			kept = append(kept, finding)
+			continue
+		}
+
+		reason := answer.Reason
+		consensus.JudgeReason = &reason
+		if !answer.Supported {
+			consensus.JudgeVerdict = webassess.JudgeVerdictRejected.Ptr()
+			detail := "rejected by the judge model: " + answer.Reason
+			finding.Verification = &webassess.FindingVerification{
+				Status: webassess.VerificationStatusRefuted,
+				Method: webassess.VerificationMethodModel,
+				Detail: &detail,
+			}
+			assessment.DiscardedFindings = append(assessment.DiscardedFindings, finding)
+			continue
+		}
+		consensus.JudgeVerdict = webassess.JudgeVerdictSupported.Ptr()
+		kept = append(kept, finding)
+	}
+	assessment.Findings = kept
+
+	reconcileSummaryFlags(assessment)
+}
+
+// modelReporter names the progress tasks of a model after it, so that the analyses of the same target by different
+// models can be told apart.
+type modelReporter struct {
+	reporter progress.Reporter
+	model    string
+}
+
+func (r modelReporter) Report(event progress.Event) {
+	event.Task = fmt.Sprintf("%s (%s)", event.Task, r.model)
+	r.reporter.Report(event)
+}
+
+// withModel names the progress tasks reported to the reporter after the model, or returns nil if the reporter is nil.
+func withModel(reporter progress.Reporter, model string) progress.Reporter {
+	if reporter == nil {
+		return nil
+	}
+	return modelReporter{reporter: reporter, model: model}
+}
+
+func isVerified(finding *webassess.Finding) bool {
+	return finding.Verification != nil && finding.Verification.Status == webassess.VerificationStatusVerified
+}
+
+func firstNonEmpty(current string, candidate string) string {
+	if current != "" {
+		return current
+	}
+	return strings.TrimSpace(candidate)
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
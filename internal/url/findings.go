@@ -0,0 +1,160 @@
+package url
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+)
+
+type modelFinding struct {
+	Title       string `json:"title"`
+	Category    string `json:"category"`
+	Severity    string `json:"severity"`
+	Description string `json:"description"`
+	Evidence    string `json:"evidence"`
+}
+
+type modelAssessment struct {
+	CodeSummary              string         `json:"codeSummary"`
+	PotentialVulnerabilities bool           `json:"potentialVulnerabilities"`
+	VulnerabilitiesSummary   *string        `json:"vulnerabilitiesSummary"`
+	PotentialSensitiveData   bool           `json:"potentialSensitiveData"`
+	SensitiveDataSummary     *string        `json:"sensitiveDataSummary"`
+	Findings                 []modelFinding `json:"findings"`
+}
+
+// ParseAssessment extracts the JSON assessment from the raw model output and converts it into a UrlAssessment. Models
+// frequently wrap their JSON in code fences or add surrounding prose, so only the outermost JSON object is parsed.
+// Category and severity values are normalized, and findings without a title are dropped.
+func ParseAssessment(output string) (*webassess.UrlAssessment, error) {
+	raw, err := extractJSONObject(output)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed modelAssessment
+	if err := json.Unmarshal([]byte(raw), &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse model output: %v", err)
+	}
+
+	assessment := &webassess.UrlAssessment{
+		CodeSummary:              parsed.CodeSummary,
+		PotentialVulnerabilities: parsed.PotentialVulnerabilities,
+		PotentialSensitiveData:   parsed.PotentialSensitiveData,
+		Findings:                 []*webassess.Finding{},
+	}
+	if parsed.VulnerabilitiesSummary != nil {
+		assessment.VulnerabilitiesSummary = *parsed.VulnerabilitiesSummary
+	}
+	if parsed.SensitiveDataSummary != nil {
+		assessment.SensitiveDataSummary = *parsed.SensitiveDataSummary
+	}
+
+	for _, f := range parsed.Findings {
+		if strings.TrimSpace(f.Title) == "" {
+			continue
+		}
+		finding := &webassess.Finding{
+			Title:       strings.TrimSpace(f.Title),
+			Category:    normalizeCategory(f.Category),
+			Severity:    NormalizeSeverity(f.Severity),
+			Description: strings.TrimSpace(f.Description),
+		}
+		if evidence := strings.TrimSpace(f.Evidence); evidence != "" {
+			finding.Evidence = &evidence
+		}
+		finding.Fingerprint = FindingFingerprint(finding)
+		assessment.Findings = append(assessment.Findings, finding)
+	}
+
+	return assessment, nil
+}
+
+// FindingFingerprint returns a stable identifier for a finding. It is derived from the finding's category and its
+// evidence when present, falling back to the title, so that the same issue reported on different runs or by
+// different models maps to the same fingerprint.
+func FindingFingerprint(finding *webassess.Finding) string {
+	basis := finding.Title
+	if finding.Evidence != nil && *finding.Evidence != "" {
+		basis = *finding.Evidence
+	}
+	sum := sha256.Sum256([]byte(string(finding.Category) + "\x00" + normalizeWhitespace(strings.ToLower(basis))))
+	return hex.EncodeToString(sum[:16])
+}
+
+// NormalizeSeverity maps the free-form severity produced by a model onto the Severity enum, defaulting to MEDIUM when
+// the value is not recognized.
+func NormalizeSeverity(severity string) webassess.Severity {
+	switch strings.ToUpper(strings.TrimSpace(severity)) {
+	case "CRITICAL":
+		return webassess.SeverityCritical
+	case "HIGH":
+		return webassess.SeverityHigh
+	case "LOW":
+		return webassess.SeverityLow
+	case "INFO", "INFORMATIONAL", "NONE":
+		return webassess.SeverityInfo
+	default:
+		return webassess.SeverityMedium
+	}
+}
+
+// SeverityRank orders severities from INFO (0) to CRITICAL (4).
+func SeverityRank(severity webassess.Severity) int {
+	switch severity {
+	case webassess.SeverityCritical:
+		return 4
+	case webassess.SeverityHigh:
+		return 3
+	case webassess.SeverityMedium:
+		return 2
+	case webassess.SeverityLow:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func downgradeSeverity(severity webassess.Severity) webassess.Severity {
+	switch severity {
+	case webassess.SeverityCritical:
+		return webassess.SeverityHigh
+	case webassess.SeverityHigh:
+		return webassess.SeverityMedium
+	case webassess.SeverityMedium:
+		return webassess.SeverityLow
+	default:
+		return webassess.SeverityInfo
+	}
+}
+
+func normalizeCategory(category string) webassess.FindingCategory {
+	normalized := strings.ToUpper(strings.TrimSpace(category))
+	normalized = strings.NewReplacer("-", "_", " ", "_").Replace(normalized)
+	switch normalized {
+	case "SENSITIVE_DATA", "SENSITIVE", "SECRET", "SECRETS":
+		return webassess.FindingCategorySensitiveData
+	case "PROMPT_INJECTION", "INJECTION", "HIDDEN_INSTRUCTIONS":
+		return webassess.FindingCategoryPromptInjection
+	default:
+		return webassess.FindingCategoryVulnerability
+	}
+}
+
+func extractJSONObject(output string) (string, error) {
+	start := strings.Index(output, "{")
+	end := strings.LastIndex(output, "}")
+	if start == -1 || end <= start {
+		return "", errors.New("model output does not contain a JSON object")
+	}
+	return output[start : end+1], nil
+}
+
+func normalizeWhitespace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,235 @@
+package url
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+)
+
+// hiddenSegment is text of the content that visitors of the page do not see, while crawlers and AI agents reading the
+// HTML do. Start and end delimit the element or comment that hides it, and innerStart and innerEnd its content.
+type hiddenSegment struct {
+	technique  webassess.HiddenTextTechnique
+	text       string
+	start      int
+	end        int
+	innerStart int
+	innerEnd   int
+}
+
+var (
+	// opaqueElementPattern matches the elements whose content is not text of the page.
+	opaqueElementPattern = regexp.MustCompile(`(?is)<script\b[^>]*>.*?</script\s*>|<style\b[^>]*>.*?</style\s*>`)
+	commentPattern       = regexp.MustCompile(`(?s)<!--(.*?)-->`)
+	openTagPattern       = regexp.MustCompile(`(?is)<([a-z][a-z0-9-]*)((?:\s+[^\s"'>/=]+(?:\s*=\s*(?:"[^"]*"|'[^']*'|[^\s"'>]+))?)*)\s*(/?)>`)
+	attributePattern     = regexp.MustCompile(`(?s)([^\s"'>/=]+)(?:\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+)))?`)
+	tagPattern           = regexp.MustCompile(`(?s)<[^>]*>`)
+	tagNamePattern       = regexp.MustCompile(`(?i)<(/?)([a-z][a-z0-9-]*)\b[^>]*>`)
+	offscreenPattern     = regexp.MustCompile(`^-(\d+(?:\.\d+)?)(px)?$`)
+)
+
+// voidElements cannot have content, so they never hide text.
+var voidElements = map[string]bool{
+	"area": true, "base": true, "br": true, "col": true, "embed": true, "hr": true, "img": true, "input": true,
+	"link": true, "meta": true, "source": true, "track": true, "wbr": true,
+}
+
+// offscreenProperties position an element, and move it out of view with a large negative offset.
+var offscreenProperties = []string{"left", "top", "right", "text-indent", "margin-left", "margin-top"}
+
+// minOffscreenPixels is the negative offset beyond which an element is considered to be moved out of view.
+const minOffscreenPixels = 999
+
+// extractHiddenText returns the text of the HTML comments and of the elements that are hidden with attributes or inline
+// styles, in the order it appears. Elements nested in a hidden element are part of its text. The HTML is scanned
+// rather than parsed, so styles applied from stylesheets and classes are not considered.
+func extractHiddenText(content string) []hiddenSegment {
+	// Scripts and styles are masked with spaces of the same length, so that offsets still point into the content
+	masked := []byte(content)
+	for _, loc := range opaqueElementPattern.FindAllStringIndex(content, -1) {
+		maskRange(masked, loc[0], loc[1])
+	}
+
+	segments := []hiddenSegment{}
+	for _, loc := range commentPattern.FindAllStringSubmatchIndex(string(masked), -1) {
+		inner := content[loc[2]:loc[3]]
+		trimmed := strings.TrimSpace(inner)
+		// Conditional comments are markup for old browsers rather than text
+		if !strings.HasPrefix(trimmed, "[if") && !strings.HasPrefix(trimmed, "<![endif") {
+			segments = append(segments, hiddenSegment{
+				technique:  webassess.HiddenTextTechniqueComment,
+				text:       normalizeWhitespace(html.UnescapeString(trimmed)),
+				start:      loc[0],
+				end:        loc[1],
+				innerStart: loc[2],
+				innerEnd:   loc[3],
+			})
+		}
+		maskRange(masked, loc[0], loc[1])
+	}
+
+	text := string(masked)
+	// The opening and closing tags are matched once, and searched for the end of every hidden element
+	tags := tagNamePattern.FindAllStringSubmatchIndex(text, -1)
+	hiddenUntil := 0
+	for _, loc := range openTagPattern.FindAllStringSubmatchIndex(text, -1) {
+		if loc[0] < hiddenUntil {
+			continue
+		}
+		name := strings.ToLower(text[loc[2]:loc[3]])
+		if voidElements[name] || loc[6] != loc[7] {
+			continue
+		}
+		technique, ok := hidingTechnique(name, text[loc[4]:loc[5]])
+		if !ok {
+			continue
+		}
+		innerEnd, end := closingTag(text, tags, name, loc[1])
+		segments = append(segments, hiddenSegment{
+			technique:  technique,
+			text:       normalizeWhitespace(html.UnescapeString(tagPattern.ReplaceAllString(text[loc[1]:innerEnd], " "))),
+			start:      loc[0],
+			end:        end,
+			innerStart: loc[1],
+			innerEnd:   innerEnd,
+		})
+		hiddenUntil = end
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].start < segments[j].start })
+	return segments
+}
+
+// closingTag finds the tag that closes the element opened before offset among the tags of the text, counting nested
+// elements of the same name. It returns the offsets of the start and the end of the closing tag, or the end of the
+// content if the element is never closed.
+func closingTag(text string, tags [][]int, name string, offset int) (int, int) {
+	depth := 1
+	for _, loc := range tags[sort.Search(len(tags), func(i int) bool { return tags[i][0] >= offset }):] {
+		if !strings.EqualFold(text[loc[4]:loc[5]], name) {
+			continue
+		}
+		if loc[2] == loc[3] {
+			depth++
+			continue
+		}
+		depth--
+		if depth == 0 {
+			return loc[0], loc[1]
+		}
+	}
+	return len(text), len(text)
+}
+
+// hidingTechnique returns how the attributes of an element hide it from visitors, if they do.
+func hidingTechnique(name string, attributes string) (webassess.HiddenTextTechnique, bool) {
+	values := map[string]string{}
+	for _, match := range attributePattern.FindAllStringSubmatch(attributes, -1) {
+		values[strings.ToLower(match[1])] = match[2] + match[3] + match[4]
+	}
+
+	if _, ok := values["hidden"]; ok {
+		return webassess.HiddenTextTechniqueHiddenAttribute, true
+	}
+	if strings.EqualFold(strings.TrimSpace(values["aria-hidden"]), "true") {
+		return webassess.HiddenTextTechniqueAriaHidden, true
+	}
+	// Legacy font colors are shown on the white default background of the page
+	if name == "font" && normalizeColor(values["color"]) == "#ffffff" {
+		return webassess.HiddenTextTechniqueSameColor, true
+	}
+
+	style := parseStyle(values["style"])
+	switch {
+	case style["display"] == "none":
+		return webassess.HiddenTextTechniqueDisplayNone, true
+	case style["visibility"] == "hidden" || style["visibility"] == "collapse":
+		return webassess.HiddenTextTechniqueVisibilityHidden, true
+	case isZero(style["opacity"]):
+		return webassess.HiddenTextTechniqueZeroOpacity, true
+	case isZero(style["font-size"]):
+		return webassess.HiddenTextTechniqueZeroFontSize, true
+	}
+	for _, property := range offscreenProperties {
+		if match := offscreenPattern.FindStringSubmatch(style[property]); match != nil {
+			if pixels, err := strconv.ParseFloat(match[1], 64); err == nil && pixels >= minOffscreenPixels {
+				return webassess.HiddenTextTechniqueOffscreen, true
+			}
+		}
+	}
+	background := style["background-color"]
+	if background == "" {
+		background = style["background"]
+	}
+	if color := normalizeColor(style["color"]); color != "" && color == normalizeColor(background) {
+		return webassess.HiddenTextTechniqueSameColor, true
+	}
+	return "", false
+}
+
+// parseStyle parses the declarations of an inline style into lowercase values without whitespace or !important.
+func parseStyle(style string) map[string]string {
+	declarations := map[string]string{}
+	for _, declaration := range strings.Split(html.UnescapeString(style), ";") {
+		property, value, ok := strings.Cut(declaration, ":")
+		if !ok {
+			continue
+		}
+		value = strings.ToLower(strings.Join(strings.Fields(value), ""))
+		value = strings.TrimSuffix(value, "!important")
+		declarations[strings.ToLower(strings.TrimSpace(property))] = value
+	}
+	return declarations
+}
+
+// isZero reports whether a CSS length or number is zero, whatever its unit.
+func isZero(value string) bool {
+	number := strings.TrimRight(value, "abcdefghijklmnopqrstuvwxyz%")
+	if number == "" {
+		return false
+	}
+	parsed, err := strconv.ParseFloat(number, 64)
+	return err == nil && parsed == 0
+}
+
+// normalizeColor converts the common notations of a color into #rrggbb, or returns the value unchanged if it is not
+// recognized, so that two notations of the same color compare equal.
+func normalizeColor(value string) string {
+	value = strings.ToLower(strings.Join(strings.Fields(value), ""))
+	switch value {
+	case "white":
+		return "#ffffff"
+	case "black":
+		return "#000000"
+	}
+	if strings.HasPrefix(value, "#") && len(value) == 4 {
+		return "#" + strings.Repeat(value[1:2], 2) + strings.Repeat(value[2:3], 2) + strings.Repeat(value[3:4], 2)
+	}
+	if strings.HasPrefix(value, "rgb(") && strings.HasSuffix(value, ")") {
+		parts := strings.Split(value[4:len(value)-1], ",")
+		if len(parts) == 3 {
+			hex := "#"
+			for _, part := range parts {
+				component, err := strconv.Atoi(part)
+				if err != nil || component < 0 || component > 255 {
+					return value
+				}
+				hex += strconv.FormatInt(int64(component)|0x100, 16)[1:]
+			}
+			return hex
+		}
+	}
+	return value
+}
+
+func maskRange(masked []byte, start int, end int) {
+	for i := start; i < end; i++ {
+		if masked[i] != '\n' {
+			masked[i] = ' '
+		}
+	}
+}
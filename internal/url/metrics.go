@@ -0,0 +1,71 @@
+package url
+
+import (
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+)
+
+// NewUsageMetrics converts the usage of a generator into the metrics reported for a target. wallTime is the time the
+// whole assessment of the target took, including fetching the content.
+func NewUsageMetrics(usage ollama.Usage, wallTime time.Duration) *webassess.UsageMetrics {
+	metrics := &webassess.UsageMetrics{
+		Generations:      usage.Requests,
+		CacheHits:        usage.CacheHits,
+		CanaryLeaks:      usage.CanaryLeaks,
+		PromptTokens:     usage.PromptTokens,
+		OutputTokens:     usage.CompletionTokens,
+		MaxSplitDepth:    usage.MaxSplitDepth,
+		WallTimeMs:       wallTime.Milliseconds(),
+		GenerationTimeMs: usage.Duration.Milliseconds(),
+		LoadTimeMs:       usage.LoadDuration.Milliseconds(),
+		PromptEvalTimeMs: usage.PromptEvalDuration.Milliseconds(),
+		EvalTimeMs:       usage.EvalDuration.Milliseconds(),
+	}
+	setThroughput(metrics, usage.PromptEvalDuration, usage.EvalDuration)
+	return metrics
+}
+
+// MergeUsageMetrics aggregates the metrics of several targets into the metrics of the run. Wall times are summed, so
+// for targets assessed concurrently the result is the total time spent rather than the elapsed time. Nil metrics are
+// skipped, and nil is returned if there are no metrics to merge.
+func MergeUsageMetrics(metrics ...*webassess.UsageMetrics) *webassess.UsageMetrics {
+	var merged *webassess.UsageMetrics
+	for _, m := range metrics {
+		if m == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &webassess.UsageMetrics{}
+		}
+		merged.Generations += m.Generations
+		merged.CacheHits += m.CacheHits
+		merged.CanaryLeaks += m.CanaryLeaks
+		merged.PromptTokens += m.PromptTokens
+		merged.OutputTokens += m.OutputTokens
+		if m.MaxSplitDepth > merged.MaxSplitDepth {
+			merged.MaxSplitDepth = m.MaxSplitDepth
+		}
+		merged.WallTimeMs += m.WallTimeMs
+		merged.GenerationTimeMs += m.GenerationTimeMs
+		merged.LoadTimeMs += m.LoadTimeMs
+		merged.PromptEvalTimeMs += m.PromptEvalTimeMs
+		merged.EvalTimeMs += m.EvalTimeMs
+	}
+	if merged != nil {
+		setThroughput(merged, time.Duration(merged.PromptEvalTimeMs)*time.Millisecond, time.Duration(merged.EvalTimeMs)*time.Millisecond)
+	}
+	return merged
+}
+
+// setThroughput derives the token throughput from the time Ollama reported spending on evaluating the prompts and
+// generating the output, so that model loading and network overhead do not skew the figures.
+func setThroughput(metrics *webassess.UsageMetrics, promptEval time.Duration, eval time.Duration) {
+	if promptEval > 0 {
+		metrics.PromptTokensPerSecond = float64(metrics.PromptTokens) / promptEval.Seconds()
+	}
+	if eval > 0 {
+		metrics.OutputTokensPerSecond = float64(metrics.OutputTokens) / eval.Seconds()
+	}
+}
@@ -0,0 +1,203 @@
+package url
+
+import (
+	"context"
+	"fmt"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// TokenCounter counts the tokens of a prompt for the model being planned for.
+type TokenCounter func(prompt string) (int, error)
+
+// estimatedBytesPerToken is used to estimate token counts when the Ollama instance cannot count them.
+const estimatedBytesPerToken = 4
+
+// PlanURLAssess fetches the target and plans its assessment without calling the model. The report contains the fetch
+// metadata and the plan, with every prompt that would be sent, instead of an assessment. Tokens are counted with the
+// Ollama instance at ollamaURL where possible, and estimated from the prompt size otherwise.
+func PlanURLAssess(ctx context.Context, target string, model ollama.Model, ollamaURL string, options Options) webassess.UrlReport {
+	log := svc1log.FromContext(ctx)
+
+	report := webassess.UrlReport{
+		Target: target,
+		Errors: []string{},
+	}
+
+	htmlContent, fetchMetadata, err := fetchHTMLContent(ctx, target, options.Fetch)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("Failed to fetch URL: %v", err))
+		return report
+	}
+	report.Fetch = fetchMetadata
+
+	estimated := false
+	counter := func(prompt string) (int, error) {
+		if !estimated {
+			tokens, err := ollama.CountTokens(ctx, ollamaURL, model, prompt)
+			if err == nil {
+				return tokens, nil
+			}
+			log.Warn("Failed to count tokens, falling back to estimates", svc1log.Stacktrace(err))
+			estimated = true
+		}
+		return (len(prompt) + estimatedBytesPerToken - 1) / estimatedBytesPerToken, nil
+	}
+
+	plan, err := PlanContent(target, htmlContent, model, options, counter)
+	if err != nil {
+		report.Errors = append(report.Errors, err.Error())
+		return report
+	}
+	plan.TokensEstimated = estimated
+	report.Plan = plan
+
+	return report
+}
+
+// PlanContent renders every prompt the analysis of the content would send, following the chunking strategy. The
+// context window is taken from the num_ctx generation option, or the model's context window if that is not set. When
+// it is known, prompts that exceed it are marked as such and split in half the same way as during the assessment;
+// such prompts are listed but not counted as generations. Synthesis prompts reference the output of the prompts they
+// combine by their 1-based position in the plan, since that output is not known ahead of time. The prompts sent to
+// verify findings depend on the findings and are not part of the plan.
+func PlanContent(target string, content string, model ollama.Model, options Options, counter TokenCounter) (*webassess.AssessmentPlan, error) {
+	planner := &planner{
+		analysis:  options.Prompts.AnalysisPrompt(target, options.Hints),
+		synthesis: options.Prompts.SynthesisPrompt(target, options.Hints),
+		counter:   counter,
+		plan: &webassess.AssessmentPlan{
+			Model:    model.Name,
+			Strategy: string(ChunkingRecursive),
+			Prompts:  []*webassess.PlannedPrompt{},
+		},
+	}
+	if options.Generation.NumCtx != nil {
+		planner.contextWindow = *options.Generation.NumCtx
+	} else {
+		planner.contextWindow = model.ContextWindowSize
+	}
+	if planner.contextWindow > 0 {
+		contextWindow := planner.contextWindow
+		planner.plan.ContextWindow = &contextWindow
+	}
+
+	if options.Chunking.Strategy == ChunkingFixed {
+		planner.plan.Strategy = string(ChunkingFixed)
+		chunks := ollama.SplitContent(content, options.Chunking.Size)
+		outputs := make([]int, 0, len(chunks))
+		for i, chunk := range chunks {
+			output, err := planner.planChunk(ollama.Chunk{Content: chunk, Index: i, Count: len(chunks)})
+			if err != nil {
+				return nil, err
+			}
+			outputs = append(outputs, output)
+		}
+		for len(outputs) > 1 {
+			combined := make([]int, 0, (len(outputs)+1)/2)
+			for i := 0; i < len(outputs); i += 2 {
+				if i+1 == len(outputs) {
+					combined = append(combined, outputs[i])
+					continue
+				}
+				output, err := planner.planSynthesis(outputs[i], outputs[i+1], planner.plan.Prompts[outputs[i]-1].ChunkIndex, 0)
+				if err != nil {
+					return nil, err
+				}
+				combined = append(combined, output)
+			}
+			outputs = combined
+		}
+	} else {
+		if _, err := planner.planChunk(ollama.Chunk{Content: content, Index: 0, Count: 1}); err != nil {
+			return nil, err
+		}
+	}
+
+	plan := planner.plan
+	for _, prompt := range plan.Prompts {
+		if prompt.ExceedsContext {
+			continue
+		}
+		plan.TotalPromptTokens += prompt.PromptTokens
+		if prompt.Kind == webassess.PromptKindAnalysis {
+			plan.AnalysisGenerations++
+		} else {
+			plan.SynthesisGenerations++
+		}
+	}
+	plan.EstimatedGenerations = plan.AnalysisGenerations + plan.SynthesisGenerations
+	return plan, nil
+}
+
+type planner struct {
+	analysis      ollama.ModelPromptContentGenerator
+	synthesis     ollama.SplitOutputCombinerGenerator
+	counter       TokenCounter
+	contextWindow int
+	plan          *webassess.AssessmentPlan
+}
+
+// planChunk plans the analysis of a chunk, mirroring Generator.ProcessContentRecursively, and returns the 1-based
+// position of the prompt that produces its final output.
+func (p *planner) planChunk(chunk ollama.Chunk) (int, error) {
+	prompt := p.analysis(chunk)
+	tokens, err := p.counter(prompt.String())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %v", err)
+	}
+	exceeds := p.contextWindow > 0 && tokens > p.contextWindow && len(chunk.Content) > 1
+	position := p.add(&webassess.PlannedPrompt{
+		Kind:           webassess.PromptKindAnalysis,
+		ChunkIndex:     chunk.Index,
+		SplitDepth:     chunk.Depth,
+		ContentBytes:   int64(len(chunk.Content)),
+		PromptTokens:   tokens,
+		ExceedsContext: exceeds,
+		Prompt:         prompt.String(),
+	})
+	if !exceeds {
+		return position, nil
+	}
+
+	mid := len(chunk.Content) / 2
+	left := chunk
+	left.Content = chunk.Content[:mid]
+	left.Depth++
+	right := chunk
+	right.Content = chunk.Content[mid:]
+	right.Depth++
+
+	leftOutput, err := p.planChunk(left)
+	if err != nil {
+		return 0, err
+	}
+	rightOutput, err := p.planChunk(right)
+	if err != nil {
+		return 0, err
+	}
+	return p.planSynthesis(leftOutput, rightOutput, chunk.Index, chunk.Depth)
+}
+
+// planSynthesis plans the combination of the outputs of two prompts, and returns the position of the synthesis prompt.
+func (p *planner) planSynthesis(first int, second int, chunkIndex int, depth int) (int, error) {
+	prompt := p.synthesis(fmt.Sprintf("<output of prompt %d>", first), fmt.Sprintf("<output of prompt %d>", second))
+	tokens, err := p.counter(prompt.String())
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %v", err)
+	}
+	return p.add(&webassess.PlannedPrompt{
+		Kind:         webassess.PromptKindSynthesis,
+		ChunkIndex:   chunkIndex,
+		SplitDepth:   depth,
+		PromptTokens: tokens,
+		Prompt:       prompt.String(),
+	}), nil
+}
+
+func (p *planner) add(prompt *webassess.PlannedPrompt) int {
+	p.plan.Prompts = append(p.plan.Prompts, prompt)
+	return len(p.plan.Prompts)
+}
@@ -0,0 +1,39 @@
+package url
+
+import "regexp"
+
+// scanner is a deterministic check for a construct that warrants a detailed analysis, regardless of what the screening
+// model thinks of the chunk it appears in.
+type scanner struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// scanners flag the constructs small models most often overlook: dynamic code execution, HTML injection sinks and
+// credentials in the source.
+var scanners = []scanner{
+	{name: "eval call", pattern: regexp.MustCompile(`\beval\s*\(`)},
+	{name: "Function constructor", pattern: regexp.MustCompile(`\bnew\s+Function\s*\(`)},
+	{name: "document.write call", pattern: regexp.MustCompile(`\bdocument\.write(ln)?\s*\(`)},
+	{name: "HTML assignment", pattern: regexp.MustCompile(`\.(inner|outer)HTML\s*\+?=`)},
+	{name: "insertAdjacentHTML call", pattern: regexp.MustCompile(`\.insertAdjacentHTML\s*\(`)},
+	{name: "string timer", pattern: regexp.MustCompile(`\bset(Timeout|Interval)\s*\(\s*["'\x60]`)},
+	{name: "javascript URL", pattern: regexp.MustCompile(`(?i)\b(href|src|action)\s*=\s*["']?\s*javascript:`)},
+	{name: "message listener", pattern: regexp.MustCompile(`addEventListener\s*\(\s*["']message["']`)},
+	{name: "AWS access key", pattern: regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "private key", pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{name: "JSON web token", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.`)},
+	{name: "hardcoded credential", pattern: regexp.MustCompile(`(?i)\b(api[_-]?key|secret|passw(or)?d|access[_-]?token)["']?\s*[:=]\s*["'][^"'\s]{6,}["']`)},
+	{name: "insecure form action", pattern: regexp.MustCompile(`(?i)<form\b[^>]*\baction\s*=\s*["']?http://`)},
+}
+
+// scanChunk returns the names of the scanners that match the content.
+func scanChunk(content string) []string {
+	matched := []string{}
+	for _, s := range scanners {
+		if s.pattern.MatchString(content) {
+			matched = append(matched, s.name)
+		}
+	}
+	return matched
+}
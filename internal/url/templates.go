@@ -0,0 +1,205 @@
+package url
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/Method-Security/webassess/internal/ollama"
+)
+
+// AnalysisPromptData is the data available to analysis prompt templates. ChunkIndex and ChunkCount describe the
+// position of the chunk among the chunks the content was split into up front, and SplitDepth is the number of times
+// the chunk was halved after exceeding the model's context length. Boundary is a random token generated for every
+// prompt, which templates use to enclose the content so that the content cannot predict where it ends.
+type AnalysisPromptData struct {
+	Content    string
+	Boundary   string
+	ChunkIndex int
+	ChunkCount int
+	SplitDepth int
+	Target     string
+	Hints      []string
+}
+
+// SynthesisPromptData is the data available to synthesis prompt templates. The outputs may repeat instructions
+// embedded in the content, so Boundary is a random token generated for every prompt that templates use to enclose
+// each of them, like the content in the analysis template.
+type SynthesisPromptData struct {
+	FirstOutput  string
+	SecondOutput string
+	Boundary     string
+	Target       string
+	Hints        []string
+}
+
+// AnalysisExample is a few-shot example of the analysis: a piece of content, which is rendered with the analysis
+// template like any chunk, and the answer the model is expected to give for it.
+type AnalysisExample struct {
+	Content string
+	Answer  string
+}
+
+// PromptTemplates holds the Go text/template templates used to render the analysis and synthesis prompts, along with
+// their system message and the few-shot examples shown before every analysis prompt. The zero value renders the
+// built-in templates with DefaultSystemPrompt and no examples.
+type PromptTemplates struct {
+	analysis  *template.Template
+	synthesis *template.Template
+	system    string
+	examples  []AnalysisExample
+}
+
+var templateFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"add":   func(a int, b int) int { return a + b },
+	"upper": strings.ToUpper,
+	"lower": strings.ToLower,
+}
+
+// defaultPromptTemplates parses the built-in prompt templates once, since they are rendered for every chunk whenever no
+// custom templates are configured.
+var defaultPromptTemplates = sync.OnceValue(func() PromptTemplates {
+	templates, err := NewPromptTemplates("", "")
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in prompt templates: %v", err))
+	}
+	return templates
+})
+
+// DefaultPromptTemplates returns the built-in prompt templates.
+func DefaultPromptTemplates() PromptTemplates {
+	return defaultPromptTemplates()
+}
+
+// NewPromptTemplates parses the analysis and synthesis templates, falling back to the built-in template for either
+// one that is empty. Each template is rendered once with sample data so that mistakes such as references to unknown
+// fields are reported up front rather than during an assessment.
+func NewPromptTemplates(analysis string, synthesis string) (PromptTemplates, error) {
+	if analysis == "" {
+		analysis = DefaultAnalysisTemplate
+	}
+	if synthesis == "" {
+		synthesis = DefaultSynthesisTemplate
+	}
+
+	analysisTemplate, err := template.New("analysis").Funcs(templateFuncs).Option("missingkey=error").Parse(analysis)
+	if err != nil {
+		return PromptTemplates{}, fmt.Errorf("failed to parse analysis prompt template: %v", err)
+	}
+	if err := analysisTemplate.Execute(&bytes.Buffer{}, AnalysisPromptData{Content: "<html></html>", Boundary: randomToken("CONTENT"), ChunkCount: 1, Hints: []string{"hint"}}); err != nil {
+		return PromptTemplates{}, fmt.Errorf("invalid analysis prompt template: %v", err)
+	}
+
+	synthesisTemplate, err := template.New("synthesis").Funcs(templateFuncs).Option("missingkey=error").Parse(synthesis)
+	if err != nil {
+		return PromptTemplates{}, fmt.Errorf("failed to parse synthesis prompt template: %v", err)
+	}
+	if err := synthesisTemplate.Execute(&bytes.Buffer{}, SynthesisPromptData{FirstOutput: "{}", SecondOutput: "{}", Boundary: randomToken("OUTPUT"), Hints: []string{"hint"}}); err != nil {
+		return PromptTemplates{}, fmt.Errorf("invalid synthesis prompt template: %v", err)
+	}
+
+	return PromptTemplates{analysis: analysisTemplate, synthesis: synthesisTemplate}, nil
+}
+
+// WithSystem returns a copy of the templates that uses system as the system message of the analysis and synthesis
+// prompts, or DefaultSystemPrompt if it is empty, and shows the examples to the model before every analysis prompt.
+// Every example needs content and an answer that is a JSON object, since the model imitates the answers it is shown.
+func (t PromptTemplates) WithSystem(system string, examples []AnalysisExample) (PromptTemplates, error) {
+	for i, example := range examples {
+		if strings.TrimSpace(example.Content) == "" {
+			return PromptTemplates{}, fmt.Errorf("prompt example %d has no content", i+1)
+		}
+		var answer map[string]interface{}
+		if err := json.Unmarshal([]byte(example.Answer), &answer); err != nil {
+			return PromptTemplates{}, fmt.Errorf("the answer of prompt example %d is not a JSON object: %v", i+1, err)
+		}
+	}
+	t.system = system
+	t.examples = examples
+	return t, nil
+}
+
+// AnalysisPrompt returns a prompt generator that renders the analysis template for each chunk of the target's content.
+// The examples are rendered with the same template, as if they were the only chunk of the target, and share the
+// boundary of the prompt.
+func (t PromptTemplates) AnalysisPrompt(target string, hints []string) ollama.ModelPromptContentGenerator {
+	return func(chunk ollama.Chunk) ollama.Prompt {
+		boundary := randomToken("CONTENT")
+		examples := make([]ollama.Example, 0, len(t.examples))
+		for _, example := range t.examples {
+			examples = append(examples, ollama.Example{
+				User:      t.renderAnalysis(AnalysisPromptData{Content: example.Content, Boundary: boundary, ChunkCount: 1, Target: target, Hints: hints}),
+				Assistant: example.Answer,
+			})
+		}
+		return guardPrompt(ollama.Prompt{
+			System:   t.systemPrompt(),
+			Examples: examples,
+			User: t.renderAnalysis(AnalysisPromptData{
+				Content:    chunk.Content,
+				Boundary:   boundary,
+				ChunkIndex: chunk.Index,
+				ChunkCount: chunk.Count,
+				SplitDepth: chunk.Depth,
+				Target:     target,
+				Hints:      hints,
+			}),
+		}, boundary)
+	}
+}
+
+// SynthesisPrompt returns a combiner that renders the synthesis template for two analysis outputs of the target.
+func (t PromptTemplates) SynthesisPrompt(target string, hints []string) ollama.SplitOutputCombinerGenerator {
+	return func(firstOutput string, secondOutput string) ollama.Prompt {
+		boundary := randomToken("OUTPUT")
+		return guardPrompt(ollama.Prompt{
+			System: t.systemPrompt(),
+			User: t.renderSynthesis(SynthesisPromptData{
+				FirstOutput:  firstOutput,
+				SecondOutput: secondOutput,
+				Boundary:     boundary,
+				Target:       target,
+				Hints:        hints,
+			}),
+		}, boundary)
+	}
+}
+
+func (t PromptTemplates) systemPrompt() string {
+	if t.system == "" {
+		return DefaultSystemPrompt
+	}
+	return t.system
+}
+
+// renderAnalysis renders the analysis template. Templates are validated when they are created, so a failure here is
+// unexpected; the built-in template is used in that case so that the assessment can still proceed.
+func (t PromptTemplates) renderAnalysis(data AnalysisPromptData) string {
+	if t.analysis == nil {
+		t = DefaultPromptTemplates()
+	}
+	var buf bytes.Buffer
+	if err := t.analysis.Execute(&buf, data); err != nil {
+		buf.Reset()
+		_ = DefaultPromptTemplates().analysis.Execute(&buf, data)
+	}
+	return buf.String()
+}
+
+// renderSynthesis renders the synthesis template, falling back to the built-in template in the same way as
+// renderAnalysis.
+func (t PromptTemplates) renderSynthesis(data SynthesisPromptData) string {
+	if t.synthesis == nil {
+		t = DefaultPromptTemplates()
+	}
+	var buf bytes.Buffer
+	if err := t.synthesis.Execute(&buf, data); err != nil {
+		buf.Reset()
+		_ = DefaultPromptTemplates().synthesis.Execute(&buf, data)
+	}
+	return buf.String()
+}
@@ -2,52 +2,318 @@ package url
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
+	"strings"
+	"time"
 
 	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/cache"
 	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/progress"
 	"github.com/ollama/ollama/api"
 )
 
-func PerformURLAssess(ctx context.Context, target string, model ollama.Model) webassess.UrlReport {
+// Options controls how a URL assessment is performed.
+type Options struct {
+	// ModelVerification sends a targeted verification prompt for findings whose evidence cannot be located in the
+	// fetched content.
+	ModelVerification bool
+	// Generation holds the model parameters sent with every generation request.
+	Generation ollama.GenerationOptions
+	// Fetch controls how the target content is retrieved.
+	Fetch FetchOptions
+	// Chunking controls how content is split across generation requests.
+	Chunking ChunkingOptions
+	// Prompts holds the templates used to render the analysis and synthesis prompts. The zero value uses the built-in
+	// templates.
+	Prompts PromptTemplates
+	// Hints are additional lines of context made available to the prompt templates, such as what the target is.
+	Hints []string
+	// Cache stores model responses so that unchanged content is not analyzed again. Nil disables caching.
+	Cache *cache.Cache
+	// KeepAlive controls how long Ollama keeps the model loaded after each request. Nil uses the Ollama default.
+	KeepAlive *time.Duration
+	// Progress receives the progress of each analysis, named after its target. Nil disables progress reporting.
+	Progress progress.Reporter
+	// TargetTimeout bounds the time spent fetching and analyzing each target. Zero disables the timeout.
+	TargetTimeout time.Duration
+	// Ensemble analyzes each target with several models instead of the assessment model when it lists any models.
+	Ensemble EnsembleOptions
+	// Cascade, when set, is the larger model that analyzes the chunks flagged while screening them with the
+	// assessment model and the pattern scanners.
+	Cascade *ollama.Model
+}
+
+// ChunkingStrategy determines how content that may not fit into the model's context window is split.
+type ChunkingStrategy string
+
+const (
+	// ChunkingRecursive sends the content as a whole and only splits it in half when the model's context length is
+	// exceeded.
+	ChunkingRecursive ChunkingStrategy = "recursive"
+	// ChunkingFixed splits the content into chunks of a fixed size up front, and combines the results pairwise.
+	ChunkingFixed ChunkingStrategy = "fixed"
+)
+
+// ChunkingOptions controls how content is split across generation requests. Size is the maximum chunk size in bytes
+// used by the fixed strategy.
+type ChunkingOptions struct {
+	Strategy ChunkingStrategy
+	Size     int
+}
+
+// ParseChunkingStrategy validates a chunking strategy name, defaulting to the recursive strategy when empty.
+func ParseChunkingStrategy(strategy string) (ChunkingStrategy, error) {
+	switch ChunkingStrategy(strings.ToLower(strategy)) {
+	case "", ChunkingRecursive:
+		return ChunkingRecursive, nil
+	case ChunkingFixed:
+		return ChunkingFixed, nil
+	default:
+		return "", fmt.Errorf("invalid chunking strategy %q. Valid strategies are: recursive, fixed", strategy)
+	}
+}
+
+// FetchOptions controls how the target content is retrieved. Zero values fall back to the defaults of the Go HTTP
+// client and no limit on the body size.
+type FetchOptions struct {
+	Timeout      time.Duration
+	UserAgent    string
+	MaxBodyBytes int64
+	Headers      map[string]string
+}
+
+// PerformURLAssess fetches the target and assesses its content. If the context is cancelled or the target timeout
+// elapses, the report holds the work done so far and its status records why it is incomplete.
+func PerformURLAssess(ctx context.Context, target string, model ollama.Model, options Options) webassess.UrlReport {
+	ctx, cancel := WithTargetTimeout(ctx, options.TargetTimeout)
+	defer cancel()
+
+	start := time.Now()
 	report := webassess.UrlReport{
 		Target: target,
 		Errors: []string{},
 	}
 
 	// Step 1: Fetch HTML content from the target URL
-	htmlContent, err := fetchHTMLContent(target)
+	htmlContent, fetchMetadata, err := fetchHTMLContent(ctx, target, options.Fetch)
 	if err != nil {
 		report.Errors = append(report.Errors, fmt.Sprintf("Failed to fetch URL: %v", err))
+		report.Status = ReportStatus(ctx)
 		return report
 	}
+	report.Fetch = fetchMetadata
+
+	// Step 2: Analyze the content with the model
+	assess(ctx, &report, start, htmlContent, model, options)
+
+	report.Status = ReportStatus(ctx)
+	return report
+}
+
+// PerformContentAssess assesses content that was provided directly instead of being fetched from the target, such as
+// a file. The target only identifies the content in the report and the prompts.
+func PerformContentAssess(ctx context.Context, target string, content string, model ollama.Model, options Options) webassess.UrlReport {
+	ctx, cancel := WithTargetTimeout(ctx, options.TargetTimeout)
+	defer cancel()
 
-	// Step 2: Initialize Ollama client
+	start := time.Now()
+	report := webassess.UrlReport{
+		Target: target,
+		Errors: []string{},
+	}
+	assess(ctx, &report, start, content, model, options)
+	report.Status = ReportStatus(ctx)
+	return report
+}
+
+// WithTargetTimeout returns a context that is cancelled after the timeout, or only when cancel is called if the timeout
+// is 0.
+func WithTargetTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// ReportStatus returns the status of a report produced with the context: TIMED_OUT if its deadline elapsed, CANCELLED
+// if it was cancelled, for instance by SIGINT, and COMPLETED otherwise.
+func ReportStatus(ctx context.Context) *webassess.ReportStatus {
+	status := webassess.ReportStatusCompleted
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		status = webassess.ReportStatusTimedOut
+	case ctx.Err() != nil:
+		status = webassess.ReportStatusCancelled
+	}
+	return &status
+}
+
+func assess(ctx context.Context, report *webassess.UrlReport, start time.Time, content string, model ollama.Model, options Options) {
+	// Initialize Ollama client
 	client, err := api.ClientFromEnvironment()
-	if err != nil {
+
+	switch {
+	case err != nil:
 		report.Errors = append(report.Errors, fmt.Sprintf("Failed to create Ollama client: %v", err))
-		return report
+	case len(options.Ensemble.Models) > 0:
+		assessEnsemble(ctx, client, report, start, content, options)
+	case options.Cascade != nil:
+		assessCascade(ctx, client, report, start, content, model, options)
+	default:
+		generator := newGenerator(client, model, options)
+
+		// Analyze the content and verify the findings. An interrupted analysis still returns the assessment of the
+		// chunks that were analyzed.
+		finalOutput, assessment, err := AssessContent(ctx, generator, report.Target, content, options)
+		report.Output = finalOutput
+		report.Metrics = NewUsageMetrics(generator.Usage(), time.Since(start))
+		report.Assessment = assessment
+		if err != nil {
+			report.Errors = append(report.Errors, err.Error())
+		}
+	}
+
+	// Pages are attacker-controlled, so instructions embedded for AI models are reported whatever the models made of
+	// them
+	checkInjection(report, content)
+}
+
+// newGenerator creates a generator for the model with the generation, cache and keep alive options.
+func newGenerator(client *api.Client, model ollama.Model, options Options) *ollama.Generator {
+	generator := ollama.NewGenerator(client, model, options.Generation)
+	generator.Cache = options.Cache
+	generator.KeepAlive = options.KeepAlive
+	return generator
+}
+
+// AssessContent analyzes content that was retrieved from the target with the generator, splitting it according to the
+// chunking strategy, then parses the findings and verifies them against the content. The raw model output is returned
+// along with the assessment, even if the assessment could not be parsed. If the analysis is interrupted after some
+// chunks were analyzed, their merged assessment is returned along with the error.
+func AssessContent(ctx context.Context, generator *ollama.Generator, target string, content string, options Options) (string, *webassess.UrlAssessment, error) {
+	analysisPrompt := options.Prompts.AnalysisPrompt(target, options.Hints)
+	synthesisPrompt := options.Prompts.SynthesisPrompt(target, options.Hints)
+
+	task := progress.Start(options.Progress, target, progress.UnitChunks)
+	task.SetStatus("analyzing")
+	generator.Progress = task
+	defer func() { generator.Progress = nil }()
+
+	var output string
+	var err error
+	if options.Chunking.Strategy == ChunkingFixed {
+		chunks := ollama.SplitContent(content, options.Chunking.Size)
+		output, err = generator.ProcessContentInChunks(ctx, chunks, analysisPrompt, synthesisPrompt)
+	} else {
+		output, err = generator.ProcessContentRecursively(ctx, content, analysisPrompt, synthesisPrompt)
+	}
+	if err != nil {
+		task.Done(err)
+		var partial *ollama.PartialError
+		if errors.As(err, &partial) {
+			if assessment := mergePartialOutputs(partial.Outputs); assessment != nil {
+				// The model cannot be asked to verify findings once the analysis was interrupted, so findings are
+				// only matched against the content
+				VerifyFindings(ctx, generator, content, assessment, false)
+				recordModel(assessment, generator.Model.Name)
+				return strings.Join(partial.Outputs, "\n"), assessment, err
+			}
+		}
+		return "", nil, err
 	}
 
-	// Step 3: Process the content recursively
-	finalOutput, err := ollama.ProcessContentRecursively(ctx, client, model, htmlContent, CreateHTMLAnalysisPrompt, CreateHTMLSynthesisPrompt)
+	assessment, err := ParseAssessment(output)
 	if err != nil {
-		report.Errors = append(report.Errors, err.Error())
-		return report
+		task.Done(err)
+		return output, nil, err
 	}
+	// Verification prompts are not chunks of the content, so only the status is reported while they run
+	task.SetStatus("verifying findings")
+	generator.Progress = nil
+	VerifyFindings(ctx, generator, content, assessment, options.ModelVerification)
+	recordModel(assessment, generator.Model.Name)
+	task.Done(nil)
+	return output, assessment, nil
+}
 
-	// Step 5: Set the final report
-	report.Output = finalOutput
+// recordModel records the model that reported each finding of the assessment.
+func recordModel(assessment *webassess.UrlAssessment, model string) {
+	for _, finding := range assessment.Findings {
+		finding.Model = &model
+	}
+	for _, finding := range assessment.DiscardedFindings {
+		finding.Model = &model
+	}
+}
 
-	return report
+// mergePartialOutputs merges the responses of the chunks analyzed before an analysis was interrupted into a single
+// assessment, in place of the synthesis the model could not run. Responses that cannot be parsed are skipped, and nil
+// is returned if none could be parsed.
+func mergePartialOutputs(outputs []string) *webassess.UrlAssessment {
+	var merged *webassess.UrlAssessment
+	seen := map[string]bool{}
+	for _, output := range outputs {
+		assessment, err := ParseAssessment(output)
+		if err != nil {
+			continue
+		}
+		if merged == nil {
+			merged = &webassess.UrlAssessment{Findings: []*webassess.Finding{}}
+		}
+		merged.CodeSummary = joinSummary(merged.CodeSummary, assessment.CodeSummary)
+		merged.PotentialVulnerabilities = merged.PotentialVulnerabilities || assessment.PotentialVulnerabilities
+		merged.VulnerabilitiesSummary = joinSummary(merged.VulnerabilitiesSummary, assessment.VulnerabilitiesSummary)
+		merged.PotentialSensitiveData = merged.PotentialSensitiveData || assessment.PotentialSensitiveData
+		merged.SensitiveDataSummary = joinSummary(merged.SensitiveDataSummary, assessment.SensitiveDataSummary)
+		for _, finding := range assessment.Findings {
+			if !seen[finding.Fingerprint] {
+				seen[finding.Fingerprint] = true
+				merged.Findings = append(merged.Findings, finding)
+			}
+		}
+	}
+	return merged
 }
 
-func fetchHTMLContent(target string) (string, error) {
-	resp, err := http.Get(target)
+func joinSummary(summary string, addition string) string {
+	addition = strings.TrimSpace(addition)
+	if summary == "" || addition == "" {
+		return summary + addition
+	}
+	return summary + " " + addition
+}
+
+// FetchedContent is the content retrieved from a target, along with the metadata and headers of the response.
+type FetchedContent struct {
+	Content  string
+	Metadata *webassess.FetchMetadata
+	Header   http.Header
+}
+
+// Fetch retrieves the content of the target. Responses other than 200 OK are returned as errors.
+func Fetch(ctx context.Context, target string, options FetchOptions) (FetchedContent, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+	if err != nil {
+		return FetchedContent{}, fmt.Errorf("failed to create request: %v", err)
+	}
+	for name, value := range options.Headers {
+		req.Header.Set(name, value)
+	}
+	if options.UserAgent != "" {
+		req.Header.Set("User-Agent", options.UserAgent)
+	}
+
+	client := &http.Client{Timeout: options.Timeout}
+	resp, err := client.Do(req)
 	if err != nil {
-		return "", fmt.Errorf("failed to fetch URL: %v", err)
+		return FetchedContent{}, fmt.Errorf("failed to fetch URL: %v", err)
 	}
 	defer func() {
 		closeErr := resp.Body.Close()
@@ -61,13 +327,43 @@ func fetchHTMLContent(target string) (string, error) {
 	}()
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("failed to fetch URL: status code %d", resp.StatusCode)
+		return FetchedContent{}, fmt.Errorf("failed to fetch URL: status code %d", resp.StatusCode)
 	}
 
-	htmlContent, err := ioutil.ReadAll(resp.Body)
+	var body io.Reader = resp.Body
+	if options.MaxBodyBytes > 0 {
+		body = io.LimitReader(resp.Body, options.MaxBodyBytes)
+	}
+	content, err := ioutil.ReadAll(body)
 	if err != nil {
-		return "", fmt.Errorf("failed to read response body: %v", err)
+		return FetchedContent{}, fmt.Errorf("failed to read response body: %v", err)
 	}
 
-	return string(htmlContent), nil
+	return FetchedContent{
+		Content:  string(content),
+		Metadata: newFetchMetadata(resp, content),
+		Header:   resp.Header,
+	}, nil
+}
+
+func fetchHTMLContent(ctx context.Context, target string, options FetchOptions) (string, *webassess.FetchMetadata, error) {
+	fetched, err := Fetch(ctx, target, options)
+	if err != nil {
+		return "", nil, err
+	}
+	return fetched.Content, fetched.Metadata, nil
+}
+
+func newFetchMetadata(resp *http.Response, content []byte) *webassess.FetchMetadata {
+	sum := sha256.Sum256(content)
+	metadata := &webassess.FetchMetadata{
+		FinalUrl:      resp.Request.URL.String(),
+		StatusCode:    resp.StatusCode,
+		ContentLength: len(content),
+		ContentSha256: hex.EncodeToString(sum[:]),
+	}
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		metadata.ContentType = &contentType
+	}
+	return metadata
 }
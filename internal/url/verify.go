@@ -0,0 +1,200 @@
+package url
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+type verificationAnswer struct {
+	Supported bool    `json:"supported"`
+	Evidence  *string `json:"evidence"`
+	Reason    string  `json:"reason"`
+}
+
+// VerifyFindings checks every finding claimed by the model against the content it was derived from, since small
+// local models regularly invent issues. A finding whose evidence can be located in the content is marked VERIFIED and
+// has its line and column recorded. Otherwise, if modelVerification is set, a targeted verification prompt is sent
+// for that finding: findings the model still supports are kept as UNVERIFIED with their severity downgraded by one
+// level, and findings it rejects are marked REFUTED and moved to the assessment's discarded findings. Without model
+// verification, findings that cannot be located are downgraded and kept as UNVERIFIED.
+func VerifyFindings(ctx context.Context, generator *ollama.Generator, content string, assessment *webassess.UrlAssessment, modelVerification bool) {
+	log := svc1log.FromContext(ctx)
+
+	kept := []*webassess.Finding{}
+	for _, finding := range assessment.Findings {
+		if finding.Evidence != nil {
+			if line, column, ok := locateEvidence(content, *finding.Evidence); ok {
+				finding.Line = &line
+				finding.Column = &column
+				finding.Verification = &webassess.FindingVerification{
+					Status: webassess.VerificationStatusVerified,
+					Method: webassess.VerificationMethodEvidenceMatch,
+				}
+				kept = append(kept, finding)
+				continue
+			}
+		}
+
+		if !modelVerification {
+			downgradeFinding(finding, webassess.VerificationMethodNone, "evidence could not be located in the content")
+			kept = append(kept, finding)
+			continue
+		}
+
+		answer, err := verifyWithModel(ctx, generator, content, finding)
+		if err != nil {
+			log.Warn("Failed to verify finding with model", svc1log.SafeParam("finding", finding.Title), svc1log.Stacktrace(err))
+			downgradeFinding(finding, webassess.VerificationMethodModel, fmt.Sprintf("verification failed: %v", err))
+			kept = append(kept, finding)
+			continue
+		}
+
+		if !answer.Supported {
+			detail := answer.Reason
+			finding.Verification = &webassess.FindingVerification{
+				Status: webassess.VerificationStatusRefuted,
+				Method: webassess.VerificationMethodModel,
+				Detail: &detail,
+			}
+			assessment.DiscardedFindings = append(assessment.DiscardedFindings, finding)
+			continue
+		}
+
+		// The model may have quoted better evidence on the second attempt; if so, the finding is verified after all
+		if answer.Evidence != nil {
+			if line, column, ok := locateEvidence(content, *answer.Evidence); ok {
+				evidence := *answer.Evidence
+				finding.Evidence = &evidence
+				finding.Line = &line
+				finding.Column = &column
+				finding.Fingerprint = FindingFingerprint(finding)
+				finding.Verification = &webassess.FindingVerification{
+					Status: webassess.VerificationStatusVerified,
+					Method: webassess.VerificationMethodModel,
+				}
+				kept = append(kept, finding)
+				continue
+			}
+		}
+		downgradeFinding(finding, webassess.VerificationMethodModel, answer.Reason)
+		kept = append(kept, finding)
+	}
+	assessment.Findings = kept
+
+	reconcileSummaryFlags(assessment)
+}
+
+func verifyWithModel(ctx context.Context, generator *ollama.Generator, content string, finding *webassess.Finding) (*verificationAnswer, error) {
+	output, err := generator.ProcessContentRecursively(ctx, content, CreateFindingVerificationPrompt(finding), CreateFindingVerificationSynthesisPrompt)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := extractJSONObject(output)
+	if err != nil {
+		return nil, err
+	}
+	var answer verificationAnswer
+	if err := json.Unmarshal([]byte(raw), &answer); err != nil {
+		return nil, fmt.Errorf("failed to parse verification output: %v", err)
+	}
+	return &answer, nil
+}
+
+func downgradeFinding(finding *webassess.Finding, method webassess.VerificationMethod, detail string) {
+	original := finding.Severity
+	finding.Severity = downgradeSeverity(original)
+	finding.Verification = &webassess.FindingVerification{
+		Status:           webassess.VerificationStatusUnverified,
+		Method:           method,
+		OriginalSeverity: &original,
+	}
+	if detail != "" {
+		finding.Verification.Detail = &detail
+	}
+}
+
+// reconcileSummaryFlags clears the summary flags of a category when the model listed findings for it but none of them
+// survived verification.
+func reconcileSummaryFlags(assessment *webassess.UrlAssessment) {
+	remaining := map[webassess.FindingCategory]bool{}
+	for _, finding := range assessment.Findings {
+		remaining[finding.Category] = true
+	}
+	discarded := map[webassess.FindingCategory]bool{}
+	for _, finding := range assessment.DiscardedFindings {
+		discarded[finding.Category] = true
+	}
+
+	if discarded[webassess.FindingCategoryVulnerability] && !remaining[webassess.FindingCategoryVulnerability] {
+		assessment.PotentialVulnerabilities = false
+		assessment.VulnerabilitiesSummary = ""
+	}
+	if discarded[webassess.FindingCategorySensitiveData] && !remaining[webassess.FindingCategorySensitiveData] {
+		assessment.PotentialSensitiveData = false
+		assessment.SensitiveDataSummary = ""
+	}
+}
+
+// locateEvidence finds the evidence snippet in the content and returns its 1-based line and column. An exact match
+// is tried first, then a case-insensitive match that tolerates differences in whitespace, since models routinely
+// reflow the code they quote.
+func locateEvidence(content string, evidence string) (int, int, bool) {
+	evidence = strings.TrimSpace(evidence)
+	if evidence == "" {
+		return 0, 0, false
+	}
+
+	index := strings.Index(content, evidence)
+	if index == -1 {
+		tokens := strings.Fields(evidence)
+		for i, token := range tokens {
+			tokens[i] = regexp.QuoteMeta(token)
+		}
+		re, err := regexp.Compile(`(?i)` + strings.Join(tokens, `\s+`))
+		if err != nil {
+			return 0, 0, false
+		}
+		loc := re.FindStringIndex(content)
+		if loc == nil {
+			return 0, 0, false
+		}
+		index = loc[0]
+	}
+
+	line, column := lineAndColumn(content, index)
+	return line, column, true
+}
+
+func lineAndColumn(content string, index int) (int, int) {
+	line := strings.Count(content[:index], "\n") + 1
+	column := index - strings.LastIndex(content[:index], "\n")
+	return line, column
+}
+
+// lineOffsets holds the offsets at which the lines of a content start, so that the line and column of many offsets
+// can be looked up without scanning the content from its start every time.
+type lineOffsets []int
+
+func newLineOffsets(content string) lineOffsets {
+	offsets := lineOffsets{0}
+	for i := 0; i < len(content); i++ {
+		if content[i] == '\n' {
+			offsets = append(offsets, i+1)
+		}
+	}
+	return offsets
+}
+
+// lineAndColumn returns the line and column of the offset, in the same way as the lineAndColumn function.
+func (o lineOffsets) lineAndColumn(index int) (int, int) {
+	line := sort.Search(len(o), func(i int) bool { return o[i] > index })
+	return line, index - o[line-1] + 1
+}
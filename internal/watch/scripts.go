@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"net/http"
+	neturl "net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// scriptSourcePattern matches the src attribute of script tags, whether its value is double quoted, single quoted or
+// unquoted.
+var scriptSourcePattern = regexp.MustCompile(`(?is)<script\b[^>]*?\bsrc\s*=\s*(?:"([^"]*)"|'([^']*)'|([^\s"'>]+))`)
+
+// WatchedHeaders are the response headers of the page that are compared between checks. They are limited to headers
+// that affect the security of the page, since headers such as Date or ETag change on every response.
+var WatchedHeaders = []string{
+	"Access-Control-Allow-Credentials",
+	"Access-Control-Allow-Origin",
+	"Content-Security-Policy",
+	"Content-Security-Policy-Report-Only",
+	"Cross-Origin-Embedder-Policy",
+	"Cross-Origin-Opener-Policy",
+	"Cross-Origin-Resource-Policy",
+	"Permissions-Policy",
+	"Referrer-Policy",
+	"Server",
+	"Strict-Transport-Security",
+	"X-Content-Type-Options",
+	"X-Frame-Options",
+	"X-Powered-By",
+}
+
+// ScriptSources returns the URLs of the external scripts included by the HTML content, resolved against the URL the
+// content was served from. Scripts that are not served over HTTP, such as data URLs, are skipped, and each URL is
+// returned once in the order it first appears.
+func ScriptSources(content string, base string) []string {
+	baseURL, err := neturl.Parse(base)
+	if err != nil {
+		return nil
+	}
+
+	sources := []string{}
+	seen := map[string]bool{}
+	for _, match := range scriptSourcePattern.FindAllStringSubmatch(content, -1) {
+		src := strings.TrimSpace(match[1] + match[2] + match[3])
+		if src == "" {
+			continue
+		}
+		ref, err := neturl.Parse(src)
+		if err != nil {
+			continue
+		}
+		resolved := baseURL.ResolveReference(ref)
+		if resolved.Scheme != "http" && resolved.Scheme != "https" {
+			continue
+		}
+		resolved.Fragment = ""
+		source := resolved.String()
+		if !seen[source] {
+			seen[source] = true
+			sources = append(sources, source)
+		}
+	}
+	return sources
+}
+
+// watchedHeaders returns the values of the watched headers present in the response, with repeated headers joined.
+func watchedHeaders(header http.Header) map[string]string {
+	values := map[string]string{}
+	for _, name := range WatchedHeaders {
+		if value := header.Values(name); len(value) > 0 {
+			values[name] = strings.Join(value, ", ")
+		}
+	}
+	return values
+}
+
+// sortedKeys returns the keys of the map in sorted order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,94 @@
+package watch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+)
+
+// State is what a watcher knows about the target from its last successful check. It is persisted between checks so
+// that a restarted watcher continues to report changes against the last check rather than starting a new baseline.
+type State struct {
+	Target        string               `json:"target"`
+	ContentSha256 string               `json:"contentSha256"`
+	Headers       map[string]string    `json:"headers"`
+	Resources     map[string]*Resource `json:"resources"`
+}
+
+// Resource is the page or one of its scripts, with the hash of the content that was last assessed and the findings
+// of that assessment.
+type Resource struct {
+	URL           string                 `json:"url"`
+	Kind          webassess.ResourceKind `json:"kind"`
+	ContentSha256 string                 `json:"contentSha256"`
+	Findings      []*webassess.Finding   `json:"findings"`
+}
+
+// LoadState reads the state written by a previous watcher. A missing file is not an error, and returns a nil state.
+func LoadState(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read watch state: %v", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse watch state: %v", err)
+	}
+	return &state, nil
+}
+
+// SaveState writes the state to the path, replacing the file atomically so that an interrupted write never leaves a
+// truncated state behind.
+func SaveState(path string, state *State) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return fmt.Errorf("failed to create watch state directory: %v", err)
+	}
+	tmp, err := os.CreateTemp(dir, ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to write watch state: %v", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write watch state: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write watch state: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		_ = os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write watch state: %v", err)
+	}
+	return nil
+}
+
+// clone returns a copy of the state whose maps can be modified without affecting the original. Resources are shared,
+// since they are replaced rather than modified.
+func (s *State) clone() *State {
+	next := &State{
+		Target:        s.Target,
+		ContentSha256: s.ContentSha256,
+		Headers:       map[string]string{},
+		Resources:     map[string]*Resource{},
+	}
+	for name, value := range s.Headers {
+		next.Headers[name] = value
+	}
+	for key, resource := range s.Resources {
+		next.Resources[key] = resource
+	}
+	return next
+}
@@ -0,0 +1,301 @@
+// Package watch periodically checks a target for changes to its page, its scripts and its security headers, and only
+// assesses the content that changed since the previous check. Each check produces a WatchEvent with the differences,
+// which makes it possible to detect supply-chain injections such as a newly added skimmer script.
+package watch
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	webassess "github.com/Method-Security/webassess/generated/go"
+	"github.com/Method-Security/webassess/internal/diff"
+	"github.com/Method-Security/webassess/internal/ollama"
+	"github.com/Method-Security/webassess/internal/url"
+	"github.com/palantir/witchcraft-go-logging/wlog/svclog/svc1log"
+)
+
+// Options controls how often the target is checked. MaxChecks stops the watcher after that many checks, where 0 checks
+// until the context is cancelled. StatePath is where the state is persisted between checks; it is kept in memory only
+// if empty.
+type Options struct {
+	Interval  time.Duration
+	MaxChecks int
+	StatePath string
+}
+
+// Watcher checks a single target for changes.
+type Watcher struct {
+	target     string
+	model      ollama.Model
+	assessment url.Options
+	options    Options
+	state      *State
+	checks     int
+}
+
+// fetchedResource is the content of the page or a script retrieved during a check.
+type fetchedResource struct {
+	kind          webassess.ResourceKind
+	content       string
+	contentSha256 string
+}
+
+// New creates a watcher for the target, resuming from the state at options.StatePath if it exists.
+func New(target string, model ollama.Model, assessment url.Options, options Options) (*Watcher, error) {
+	if options.Interval <= 0 {
+		return nil, fmt.Errorf("invalid watch interval %s. The interval must be positive", options.Interval)
+	}
+	w := &Watcher{
+		target:     target,
+		model:      model,
+		assessment: assessment,
+		options:    options,
+	}
+	if options.StatePath != "" {
+		state, err := LoadState(options.StatePath)
+		if err != nil {
+			return nil, err
+		}
+		if state != nil && state.Target != target {
+			return nil, fmt.Errorf("watch state %s belongs to target %s", options.StatePath, state.Target)
+		}
+		w.state = state
+	}
+	return w, nil
+}
+
+// Run checks the target every interval until the context is cancelled or MaxChecks is reached. Events that contain
+// changes or errors, and the event of the baseline check, are passed to emit; an error returned by emit stops the
+// watcher. A check interrupted by the cancellation of the context is discarded.
+func (w *Watcher) Run(ctx context.Context, emit func(webassess.WatchEvent) error) error {
+	log := svc1log.FromContext(ctx)
+	ticker := time.NewTicker(w.options.Interval)
+	defer ticker.Stop()
+
+	for {
+		event := w.Check(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		if HasChanges(event) {
+			if err := emit(event); err != nil {
+				return err
+			}
+		}
+		if w.options.StatePath != "" && w.state != nil {
+			if err := SaveState(w.options.StatePath, w.state); err != nil {
+				return err
+			}
+		}
+		if w.options.MaxChecks > 0 && w.checks >= w.options.MaxChecks {
+			return nil
+		}
+
+		log.Debug("Waiting for next check", svc1log.SafeParam("interval", w.options.Interval.String()))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// HasChanges returns true if the event is worth emitting: it is the baseline check, or resources, headers or errors
+// were reported.
+func HasChanges(event webassess.WatchEvent) bool {
+	return event.Baseline || len(event.Resources) > 0 || len(event.ChangedHeaders) > 0 || len(event.Errors) > 0
+}
+
+// Check fetches the page and its scripts, and assesses the resources whose content changed since the previous check.
+// Inference is skipped entirely when neither the page nor any of its scripts changed. Resources whose assessment
+// failed are not recorded in the state, so that they are assessed again on the next check.
+func (w *Watcher) Check(ctx context.Context) webassess.WatchEvent {
+	log := svc1log.FromContext(ctx)
+	w.checks++
+	event := webassess.WatchEvent{
+		Target:         w.target,
+		CheckedAt:      time.Now().UTC(),
+		Check:          w.checks,
+		Baseline:       w.state == nil,
+		Resources:      []*webassess.ResourceChange{},
+		ChangedHeaders: []*webassess.HeaderChange{},
+		Errors:         []string{},
+	}
+
+	page, err := url.Fetch(ctx, w.target, w.assessment.Fetch)
+	if err != nil {
+		event.Errors = append(event.Errors, fmt.Sprintf("Failed to fetch URL: %v", err))
+		return event
+	}
+
+	previous := w.state
+	if previous == nil {
+		previous = &State{Target: w.target, Headers: map[string]string{}, Resources: map[string]*Resource{}}
+	}
+	next := previous.clone()
+
+	// Resources are kept in the order they appear in, with the page first. A script that could not be fetched is
+	// treated as unchanged rather than removed, so that a transient failure does not resolve its findings.
+	order := []string{w.target}
+	fetched := map[string]fetchedResource{w.target: newFetchedResource(webassess.ResourceKindPage, page.Content)}
+	present := map[string]string{w.target: fetched[w.target].contentSha256}
+	for _, source := range ScriptSources(page.Content, page.Metadata.FinalUrl) {
+		if source == w.target {
+			continue
+		}
+		script, err := url.Fetch(ctx, source, w.assessment.Fetch)
+		if err != nil {
+			event.Errors = append(event.Errors, fmt.Sprintf("Failed to fetch script %s: %v", source, err))
+			if resource, ok := previous.Resources[source]; ok {
+				present[source] = resource.ContentSha256
+			}
+			continue
+		}
+		order = append(order, source)
+		fetched[source] = newFetchedResource(webassess.ResourceKindScript, script.Content)
+		present[source] = fetched[source].contentSha256
+	}
+
+	headers := watchedHeaders(page.Header)
+	if !event.Baseline {
+		event.ChangedHeaders = compareHeaders(previous.Headers, headers)
+	}
+	next.Headers = headers
+
+	contentSha256 := contentHash(present)
+	event.ContentSha256 = &contentSha256
+	if !event.Baseline && contentSha256 == previous.ContentSha256 {
+		log.Debug("Content unchanged, skipping assessment", svc1log.SafeParam("target", w.target))
+		w.state = next
+		return event
+	}
+
+	metrics := []*webassess.UsageMetrics{}
+	for _, key := range order {
+		resource := fetched[key]
+		previousResource := previous.Resources[key]
+		if previousResource != nil && previousResource.ContentSha256 == resource.contentSha256 {
+			continue
+		}
+
+		log.Info("Assessing changed content", svc1log.SafeParam("url", key), svc1log.SafeParam("kind", string(resource.kind)))
+		report := url.PerformContentAssess(ctx, key, resource.content, w.model, w.assessment)
+		if ctx.Err() != nil {
+			return event
+		}
+		event.Assessed = true
+		metrics = append(metrics, report.Metrics)
+		// Content the model could not assess is retried on the next check rather than recorded with partial findings
+		if !url.ModelAssessed(report.Assessment) {
+			for _, reportError := range report.Errors {
+				event.Errors = append(event.Errors, fmt.Sprintf("Failed to assess %s: %s", key, reportError))
+			}
+			continue
+		}
+
+		change := &webassess.ResourceChange{
+			Url:                  key,
+			Kind:                 resource.kind,
+			Change:               webassess.ResourceChangeTypeAdded,
+			CurrentContentSha256: &resource.contentSha256,
+		}
+		var previousFindings []*webassess.Finding
+		if previousResource != nil {
+			change.Change = webassess.ResourceChangeTypeChanged
+			change.PreviousContentSha256 = &previousResource.ContentSha256
+			previousFindings = previousResource.Findings
+		}
+		change.NewFindings, change.ResolvedFindings, _ = diff.CompareFindings(previousFindings, report.Assessment.Findings)
+		event.Resources = append(event.Resources, change)
+
+		next.Resources[key] = &Resource{
+			URL:           key,
+			Kind:          resource.kind,
+			ContentSha256: resource.contentSha256,
+			Findings:      report.Assessment.Findings,
+		}
+	}
+
+	for _, key := range sortedKeys(previous.Resources) {
+		if _, ok := present[key]; ok {
+			continue
+		}
+		resource := previous.Resources[key]
+		event.Resources = append(event.Resources, &webassess.ResourceChange{
+			Url:                   key,
+			Kind:                  resource.Kind,
+			Change:                webassess.ResourceChangeTypeRemoved,
+			PreviousContentSha256: &resource.ContentSha256,
+			NewFindings:           []*webassess.Finding{},
+			ResolvedFindings:      resource.Findings,
+		})
+		delete(next.Resources, key)
+	}
+
+	event.Metrics = url.MergeUsageMetrics(metrics...)
+	next.ContentSha256 = stateHash(next)
+	w.state = next
+	return event
+}
+
+func newFetchedResource(kind webassess.ResourceKind, content string) fetchedResource {
+	sum := sha256.Sum256([]byte(content))
+	return fetchedResource{kind: kind, content: content, contentSha256: hex.EncodeToString(sum[:])}
+}
+
+// contentHash combines the content hashes of the page and its scripts, keyed by URL, into a single hash that changes
+// when any of them changes, or when a script is added or removed.
+func contentHash(resources map[string]string) string {
+	var builder strings.Builder
+	for _, key := range sortedKeys(resources) {
+		builder.WriteString(key)
+		builder.WriteString(" ")
+		builder.WriteString(resources[key])
+		builder.WriteString("\n")
+	}
+	sum := sha256.Sum256([]byte(builder.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// stateHash returns the content hash of the resources recorded in the state. It only matches the hash of the fetched
+// content when every changed resource was assessed successfully.
+func stateHash(state *State) string {
+	resources := map[string]string{}
+	for key, resource := range state.Resources {
+		resources[key] = resource.ContentSha256
+	}
+	return contentHash(resources)
+}
+
+// compareHeaders returns the watched headers that were added, removed or changed, sorted by name.
+func compareHeaders(previous map[string]string, current map[string]string) []*webassess.HeaderChange {
+	names := map[string]bool{}
+	for name := range previous {
+		names[name] = true
+	}
+	for name := range current {
+		names[name] = true
+	}
+
+	changes := []*webassess.HeaderChange{}
+	for _, name := range sortedKeys(names) {
+		previousValue, hadPrevious := previous[name]
+		currentValue, hasCurrent := current[name]
+		if hadPrevious == hasCurrent && previousValue == currentValue {
+			continue
+		}
+		change := &webassess.HeaderChange{Name: name}
+		if hadPrevious {
+			change.Previous = &previousValue
+		}
+		if hasCurrent {
+			change.Current = &currentValue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
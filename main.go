@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Method-Security/webassess/cmd"
 )
@@ -15,10 +18,33 @@ func main() {
 	webassess := cmd.NewWebAssess(version)
 	webassess.InitRootCommand()
 	webassess.InitURLAssess()
+	webassess.InitDiffCommand()
+	webassess.InitEvalCommand()
+	webassess.InitServeCommand()
+	webassess.InitWatchCommand()
+	webassess.InitConfigCommand()
+	webassess.InitCacheCommand()
+	webassess.InitModelsCommand()
 
-	if err := webassess.RootCmd.Execute(); err != nil {
-		os.Exit(1)
+	// SIGINT and SIGTERM cancel the context of the command instead of terminating the CLI, so that a spawned Ollama
+	// server is stopped on the way out. A second signal terminates the CLI immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	executed, err := webassess.RootCmd.ExecuteContextC(ctx)
+	if executed != nil {
+		webassess.StopOllama(executed.Context())
+	} else {
+		webassess.StopOllama(ctx)
+	}
+	webassess.Close()
+	stop()
+	if err != nil {
+		os.Exit(cmd.ExitCodeToolFailure)
 	}
 
-	os.Exit(0)
+	os.Exit(webassess.ExitCode)
 }